@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/checker"
+)
+
+// maxTerminationMessageLength is the most of a termination message that the
+// kubelet keeps.
+const maxTerminationMessageLength = 4096
+
+var (
+	probe              string
+	terminationLogPath string
+)
+
+func main() {
+	flag.Parse()
+
+	p, err := checker.DecodeProbe(probe)
+	if err != nil {
+		exit(checker.Result{Message: err.Error()})
+	}
+
+	exit(checker.Run(context.Background(), p))
+}
+
+// exit reports the result on stdout and in the termination log, then exits
+// with a status reflecting whether the check passed.
+func exit(result checker.Result) {
+	b, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding result: %s\n", err.Error())
+		os.Exit(2)
+	}
+	fmt.Println(string(b))
+	// A truncated result can't be decoded, so drop the step messages from
+	// long scripted check results rather than let the kubelet cut them off.
+	if len(b) > maxTerminationMessageLength && len(result.Steps) > 0 {
+		for i := range result.Steps {
+			result.Steps[i].Message = ""
+		}
+		b, _ = json.Marshal(result)
+	}
+	if err := ioutil.WriteFile(terminationLogPath, b, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing termination log: %s\n", err.Error())
+	}
+
+	if !result.Passed {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func init() {
+	flag.StringVar(&probe, "probe", "", "JSON encoded probe to run.")
+	flag.StringVar(&terminationLogPath, "termination-log", "/dev/termination-log", "Path to write the check result to.")
+}
@@ -1,29 +1,72 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
 	healthcontroller "github.com/mbellgb/healthcheck-controller/internal/pkg/controller"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/metrics"
 	"github.com/mbellgb/healthcheck-controller/internal/pkg/signals"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/webhook"
 	clientset "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned"
 	healthinformers "github.com/mbellgb/healthcheck-controller/pkg/generated/informers/externalversions"
+	healthv1alpha1informers "github.com/mbellgb/healthcheck-controller/pkg/generated/informers/externalversions/health/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog"
 )
 
 var (
-	kubeconfig string
-	masterURL  string
+	kubeconfig  string
+	masterURL   string
+	metricsAddr string
+
+	configFile  string
+	configFlags *config.Flags
+
+	clusterCheckNamespace string
+
+	namespaces          string
+	selector            string
+	clusterHealthChecks bool
+
+	webhookAddr string
+	tlsCertFile string
+	tlsKeyFile  string
+
+	leaderElect        bool
+	leaseNamespace     string
+	leaseName          string
+	leaseDuration      time.Duration
+	leaseRenewDeadline time.Duration
+	leaseRetryPeriod   time.Duration
 )
 
 func main() {
 	klog.InitFlags(nil)
 	flag.Parse()
 
+	controllerConfig, err := loadConfig()
+	if err != nil {
+		klog.Fatalf("Error loading configuration: %s", err.Error())
+	}
+	klog.Infof("Effective configuration:\n%s", controllerConfig)
+
 	stopCh := signals.SetupSignalHandler()
 
 	cfg, err := clientcmd.BuildConfigFromFlags(masterURL, kubeconfig)
@@ -41,25 +84,233 @@ func main() {
 		klog.Fatalf("Error building health client: %s", err.Error())
 	}
 
-	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
-	healthInformerFactory := healthinformers.NewSharedInformerFactory(healthClient, time.Second*30)
+	if _, err := labels.Parse(selector); err != nil {
+		klog.Fatalf("Error parsing selector: %s", err.Error())
+	}
+	tweakListOptions := func(options *metav1.ListOptions) {
+		options.LabelSelector = selector
+	}
+
+	// ClusterHealthChecks aren't namespaced, so a single informer for them
+	// is shared by every controller.
+	var clusterHealthInformerFactory healthinformers.SharedInformerFactory
+	var clusterhealthcheckInformer healthv1alpha1informers.ClusterHealthCheckInformer
+	if clusterHealthChecks {
+		clusterHealthInformerFactory = healthinformers.NewSharedInformerFactoryWithOptions(healthClient, controllerConfig.ResyncPeriod.Duration,
+			healthinformers.WithTweakListOptions(tweakListOptions))
+		clusterhealthcheckInformer = clusterHealthInformerFactory.Health().V1alpha1().ClusterHealthChecks()
+	}
+
+	// Informers can only watch one namespace or all of them, so there is a
+	// controller for each namespace watched.
+	var controllers []*healthcontroller.Controller
+	for _, namespace := range watchedNamespaces(namespaces) {
+		kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, controllerConfig.ResyncPeriod.Duration,
+			kubeinformers.WithNamespace(namespace))
+		// Services are only watched if they match the selector, like the
+		// HealthChecks generated for them.
+		serviceInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, controllerConfig.ResyncPeriod.Duration,
+			kubeinformers.WithNamespace(namespace), kubeinformers.WithTweakListOptions(tweakListOptions))
+		healthInformerFactory := healthinformers.NewSharedInformerFactoryWithOptions(healthClient, controllerConfig.ResyncPeriod.Duration,
+			healthinformers.WithNamespace(namespace), healthinformers.WithTweakListOptions(tweakListOptions))
+		controllers = append(controllers, healthcontroller.NewController(
+			kubeClient,
+			healthClient,
+			kubeInformerFactory.Batch().V1beta1().CronJobs(),
+			kubeInformerFactory.Batch().V1().Jobs(),
+			healthInformerFactory.Health().V1alpha1().HealthChecks(),
+			clusterhealthcheckInformer,
+			healthInformerFactory.Health().V1alpha1().HealthCheckGroups(),
+			serviceInformerFactory.Core().V1().Services(),
+			kubeInformerFactory.Core().V1().Pods(),
+			kubeInformerFactory.Core().V1().Secrets(),
+			controllerConfig,
+			clusterCheckNamespace,
+			namespace,
+		))
 
-	controller := healthcontroller.NewController(
-		kubeClient,
-		healthClient,
-		kubeInformerFactory.Batch().V1beta1().CronJobs(),
-		healthInformerFactory.Health().V1alpha1().HealthChecks(),
-	)
+		kubeInformerFactory.Start(stopCh)
+		serviceInformerFactory.Start(stopCh)
+		healthInformerFactory.Start(stopCh)
+	}
+	if clusterHealthInformerFactory != nil {
+		clusterHealthInformerFactory.Start(stopCh)
+	}
 
-	kubeInformerFactory.Start(stopCh)
-	healthInformerFactory.Start(stopCh)
+	go serveMetrics(metricsAddr, controllerConfig)
+	// Every replica serves the webhook, whether or not it is the leader.
+	go serveWebhook(webhookAddr, tlsCertFile, tlsKeyFile, controllerConfig)
 
-	if err = controller.Run(2, stopCh); err != nil {
-		klog.Fatalf("Error starting controller: %s\n", err.Error())
+	run := func(stopCh <-chan struct{}) {
+		var wg sync.WaitGroup
+		for _, controller := range controllers {
+			wg.Add(1)
+			go func(controller *healthcontroller.Controller) {
+				defer wg.Done()
+				if err := controller.Run(controllerConfig.Workers, stopCh); err != nil {
+					klog.Fatalf("Error starting controller: %s\n", err.Error())
+				}
+			}(controller)
+		}
+		wg.Wait()
+	}
+	if !leaderElect {
+		run(stopCh)
+		return
+	}
+
+	id, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("Error getting hostname: %s", err.Error())
+	}
+	// Replicas on the same host still need distinct identities.
+	id = id + "_" + string(uuid.NewUUID())
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: leaseNamespace,
+			Name:      leaseName,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: id,
+		},
+	}
+
+	// Cancelling the context when a shutdown signal is received releases the
+	// lease, so another replica can take over without waiting for it to
+	// expire.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	metrics.SetLeader(false)
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		ReleaseOnCancel: true,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   leaseRenewDeadline,
+		RetryPeriod:     leaseRetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.Infof("Started leading as %s", id)
+				metrics.SetLeader(true)
+				run(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				metrics.SetLeader(false)
+				if ctx.Err() != nil {
+					klog.Infof("Stopped leader election as %s", id)
+					return
+				}
+				klog.Fatalf("Lost leadership as %s", id)
+			},
+			OnNewLeader: func(identity string) {
+				if identity != id {
+					klog.Infof("Current leader is %s", identity)
+				}
+			},
+		},
+	})
+}
+
+// serveMetrics serves Prometheus metrics on /metrics, and the effective
+// configuration on /debug/config.
+func serveMetrics(addr string, cfg *config.Config) {
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/debug/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(cfg); err != nil {
+			klog.Errorf("Error writing configuration: %s", err.Error())
+		}
+	})
+	klog.Infof("Serving metrics on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		klog.Fatalf("Error serving metrics: %s", err.Error())
+	}
+}
+
+// serveWebhook serves the admission webhooks over HTTPS.
+func serveWebhook(addr, certFile, keyFile string, cfg *config.Config) {
+	if addr == "" {
+		return
+	}
+	klog.Infof("Serving admission webhooks on %s", addr)
+	if err := http.ListenAndServeTLS(addr, certFile, keyFile, webhook.NewHandler(cfg)); err != nil {
+		klog.Fatalf("Error serving admission webhooks: %s", err.Error())
 	}
 }
 
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig if out of cluster. Ignore to use in-cluster-config.")
 	flag.StringVar(&masterURL, "master", "", "Address of k8s API if out of cluster. Ignore to use in-cluster-config.")
+	flag.StringVar(&configFile, "config", "", "Path to a "+config.Kind+" file. Its settings are overridden by their environment variables and flags.")
+	configFlags = config.AddFlags(flag.CommandLine)
+	flag.StringVar(&clusterCheckNamespace, "cluster-check-namespace", podNamespace(), "Namespace ClusterHealthChecks run in when they don't set an executionNamespace. Defaults to the POD_NAMESPACE environment variable, or default.")
+	flag.StringVar(&namespaces, "namespaces", "", "Comma separated namespaces to watch. Watches every namespace if empty.")
+	flag.StringVar(&selector, "selector", "", "Label selector restricting the HealthChecks, ClusterHealthChecks, HealthCheckGroups and Services the controller watches, eg team=checkout.")
+	flag.BoolVar(&clusterHealthChecks, "cluster-health-checks", true, "Sync ClusterHealthChecks. When watching a set of namespaces, only those running in them are synced. Disable to run without permission to watch ClusterHealthChecks.")
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "Address to serve Prometheus metrics on. Set to an empty string to disable.")
+	flag.StringVar(&webhookAddr, "webhook-addr", "", "Address to serve the validating and defaulting admission webhooks on, eg :8443. Disabled if empty.")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "/etc/hc-controller/tls/tls.crt", "Certificate the admission webhooks are served with.")
+	flag.StringVar(&tlsKeyFile, "tls-private-key-file", "/etc/hc-controller/tls/tls.key", "Private key matching -tls-cert-file.")
+	flag.BoolVar(&leaderElect, "leader-elect", false, "Elect a leader among controller replicas, so that only one is active at a time. Needs permission to get, create and update Leases.")
+	flag.StringVar(&leaseNamespace, "leader-elect-namespace", podNamespace(), "Namespace of the Lease used for leader election. Defaults to the POD_NAMESPACE environment variable, or default.")
+	flag.StringVar(&leaseName, "leader-elect-name", "hc-controller", "Name of the Lease used for leader election.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", 15*time.Second, "How long replicas wait before trying to take over from a leader that stopped renewing the Lease.")
+	flag.DurationVar(&leaseRenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "How long the leader retries renewing the Lease before giving up leadership.")
+	flag.DurationVar(&leaseRetryPeriod, "leader-elect-retry-period", 2*time.Second, "How long replicas wait between attempts to acquire or renew the Lease.")
+}
+
+// loadConfig returns the controller's configuration, built from the defaults,
+// the configuration file, environment variables and flags, each overriding the
+// last.
+func loadConfig() (*config.Config, error) {
+	cfg := config.Default()
+	if configFile != "" {
+		var err error
+		if cfg, err = config.Load(configFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	configFlags.Apply(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// watchedNamespaces returns the distinct namespaces in a comma separated
+// list, or every namespace if it is empty.
+func watchedNamespaces(list string) []string {
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, namespace := range strings.Split(list, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" && !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
+	return namespaces
+}
+
+// podNamespace returns the namespace the controller is running in, if it is
+// known.
+func podNamespace() string {
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	return metav1.NamespaceDefault
 }
@@ -3,12 +3,15 @@ module github.com/mbellgb/healthcheck-controller
 go 1.12
 
 require (
+	github.com/prometheus/client_golang v1.0.0
+	github.com/robfig/cron/v3 v3.0.1
 	k8s.io/api v0.17.5
 	k8s.io/apimachinery v0.17.5
 	k8s.io/client-go v0.17.5
 	k8s.io/code-generator v0.17.5
 	k8s.io/klog v1.0.0
 	k8s.io/utils v0.0.0-20200414100711-2df71ebbae66
+	sigs.k8s.io/yaml v1.1.0
 )
 
 replace (
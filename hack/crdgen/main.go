@@ -0,0 +1,74 @@
+// Command crdgen generates the ClusterHealthCheck CRD from the HealthCheck CRD,
+// so that the two share one schema. Run it with hack/update-crds.sh.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// header marks the generated CRD, so that it isn't edited by hand.
+const header = "# Code generated by hack/crdgen from healthcheck.yaml. DO NOT EDIT.\n"
+
+// replacement replaces old with new in the HealthCheck CRD. count is how many
+// times old must appear, or -1 for any number of times.
+type replacement struct {
+	old, new string
+	count    int
+}
+
+var replacements = []replacement{
+	{old: "  name: healthchecks.health.mbell.dev\n", new: "  name: clusterhealthchecks.health.mbell.dev\n", count: 1},
+	{old: "    kind: HealthCheck\n", new: "    kind: ClusterHealthCheck\n", count: 1},
+	{old: "    plural: healthchecks\n", new: "    plural: clusterhealthchecks\n", count: 1},
+	{old: "  scope: Namespaced\n", new: "  scope: Cluster\n", count: 1},
+	{
+		old: "          properties:\n            frequency:\n",
+		new: "          properties:\n" +
+			"            executionNamespace:\n" +
+			"              description: Namespace the check's Jobs and Pods run in, and that Pods sampled by a pods check and Secrets referenced by alerting are read from. Defaults to the controller's -cluster-check-namespace.\n" +
+			"              type: string\n" +
+			"            frequency:\n",
+		count: 1,
+	},
+	// Everything a ClusterHealthCheck reads from a namespace is read from
+	// its execution namespace.
+	{old: "the HealthCheck's namespace", new: "the execution namespace", count: -1},
+}
+
+// generate returns the ClusterHealthCheck CRD for the given HealthCheck CRD.
+func generate(healthcheck []byte) ([]byte, error) {
+	crd := healthcheck
+	for _, r := range replacements {
+		n := bytes.Count(crd, []byte(r.old))
+		if n == 0 || (r.count >= 0 && n != r.count) {
+			return nil, fmt.Errorf("expected %q to appear %d times in the HealthCheck CRD, found it %d times", r.old, r.count, n)
+		}
+		crd = bytes.Replace(crd, []byte(r.old), []byte(r.new), -1)
+	}
+	return append([]byte(header), crd...), nil
+}
+
+func main() {
+	in := flag.String("in", "artifacts/crds/healthcheck.yaml", "HealthCheck CRD to generate from.")
+	out := flag.String("out", "artifacts/crds/clusterhealthcheck.yaml", "File to write the ClusterHealthCheck CRD to.")
+	flag.Parse()
+
+	healthcheck, err := ioutil.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	crd, err := generate(healthcheck)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(*out, crd, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
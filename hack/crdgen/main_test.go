@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestClusterHealthCheckCRDUpToDate(t *testing.T) {
+	healthcheck, err := ioutil.ReadFile("../../artifacts/crds/healthcheck.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected, err := generate(healthcheck)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	actual, err := ioutil.ReadFile("../../artifacts/crds/clusterhealthcheck.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("artifacts/crds/clusterhealthcheck.yaml is out of date. Please run hack/update-crds.sh")
+	}
+}
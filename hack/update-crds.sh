@@ -0,0 +1,12 @@
+#!/usr/bin/env bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(dirname "${BASH_SOURCE[0]}")/..
+
+# The ClusterHealthCheck CRD is generated from the HealthCheck CRD, which is
+# the one to edit.
+cd "${SCRIPT_ROOT}"
+go run ./hack/crdgen
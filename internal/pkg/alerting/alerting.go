@@ -0,0 +1,235 @@
+// Package alerting sends the notifications configured by a HealthCheck's
+// alerting spec to webhook, Slack and PagerDuty receivers.
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"text/template"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+const (
+	// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint.
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+	defaultTemplate = `{{if .Resolved}}HealthCheck {{.Namespace}}/{{.Name}} is healthy again{{else}}HealthCheck {{.Namespace}}/{{.Name}} is unhealthy{{end}}, average healthiness {{printf "%.2f" .AverageHealthiness}}`
+	sendTimeout     = 10 * time.Second
+)
+
+// Alert is the state of a HealthCheck that a notification is sent for. It is
+// the data given to receiver templates.
+type Alert struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Resolved is true if the HealthCheck is healthy again.
+	Resolved           bool      `json:"resolved"`
+	AverageHealthiness float32   `json:"averageHealthiness"`
+	Last10             []bool    `json:"last10,omitempty"`
+	Time               time.Time `json:"time"`
+}
+
+// Receiver is an AlertReceiver with any values from Secrets filled in.
+type Receiver struct {
+	Name       string
+	Type       healthv1alpha1.AlertReceiverType
+	URL        string
+	RoutingKey string
+	Template   string
+}
+
+// Notifier sends notifications to receivers.
+type Notifier interface {
+	Notify(receiver Receiver, alert Alert) error
+}
+
+// HTTPNotifier sends notifications over HTTP.
+type HTTPNotifier struct {
+	Client *http.Client
+}
+
+// NewHTTPNotifier returns a Notifier that gives up on requests after a
+// timeout and won't connect to link-local addresses, such as cloud metadata
+// endpoints.
+func NewHTTPNotifier() *HTTPNotifier {
+	dialer := &net.Dialer{Timeout: sendTimeout, Control: refuseLinkLocal}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: sendTimeout,
+	}
+	return &HTTPNotifier{Client: &http.Client{Timeout: sendTimeout, Transport: transport}}
+}
+
+// refuseLinkLocal stops connections to link-local addresses. It runs after
+// name resolution, so it also catches names and redirects that resolve to
+// them.
+func refuseLinkLocal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && (ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()) {
+		return fmt.Errorf("refusing to connect to link-local address %s", host)
+	}
+	return nil
+}
+
+// ValidateURL checks that a receiver URL is an absolute http or https URL.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("couldn't parse URL: %s", err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL must have a host")
+	}
+	return nil
+}
+
+// Notify sends the alert to the receiver.
+func (n *HTTPNotifier) Notify(receiver Receiver, alert Alert) error {
+	message, err := Message(receiver.Template, alert)
+	if err != nil {
+		return err
+	}
+	payload, err := newPayload(receiver, alert, message)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	target := receiver.URL
+	if target == "" && receiver.Type == healthv1alpha1.AlertReceiverPagerDuty {
+		target = DefaultPagerDutyURL
+	}
+	// URLs read from Secrets haven't been validated with the spec.
+	if err := ValidateURL(target); err != nil {
+		return fmt.Errorf("receiver %q: %s", receiver.Name, err.Error())
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("receiver %q returned status %d", receiver.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// ParseTemplate checks that a receiver template can be used.
+func ParseTemplate(text string) error {
+	_, err := parseTemplate(text)
+	return err
+}
+
+// Message renders the receiver template, or the default template if it is
+// empty, for the alert.
+func Message(text string, alert Alert) (string, error) {
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, alert); err != nil {
+		return "", fmt.Errorf("couldn't render template: %s", err.Error())
+	}
+	return b.String(), nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultTemplate
+	}
+	tmpl, err := template.New("alert").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse template: %s", err.Error())
+	}
+	return tmpl, nil
+}
+
+// webhookPayload is the body posted to generic webhook receivers.
+type webhookPayload struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Alert   Alert  `json:"alert"`
+}
+
+// slackPayload is the body posted to Slack incoming webhooks.
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// pagerDutyPayload is a PagerDuty Events API v2 event.
+type pagerDutyPayload struct {
+	RoutingKey  string               `json:"routing_key"`
+	EventAction string               `json:"event_action"`
+	DedupKey    string               `json:"dedup_key"`
+	Payload     pagerDutyEventDetail `json:"payload"`
+}
+
+type pagerDutyEventDetail struct {
+	Summary  string `json:"summary"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+}
+
+func newPayload(receiver Receiver, alert Alert, message string) (interface{}, error) {
+	switch receiver.Type {
+	case healthv1alpha1.AlertReceiverWebhook:
+		status := "firing"
+		if alert.Resolved {
+			status = "resolved"
+		}
+		return webhookPayload{Status: status, Message: message, Alert: alert}, nil
+	case healthv1alpha1.AlertReceiverSlack:
+		return slackPayload{Text: message}, nil
+	case healthv1alpha1.AlertReceiverPagerDuty:
+		action := "trigger"
+		if alert.Resolved {
+			action = "resolve"
+		}
+		// Triggers and resolves for the same HealthCheck share a dedup
+		// key, so PagerDuty groups them into one incident.
+		key := alert.Namespace + "/" + alert.Name
+		return pagerDutyPayload{
+			RoutingKey:  receiver.RoutingKey,
+			EventAction: action,
+			DedupKey:    key,
+			Payload: pagerDutyEventDetail{
+				Summary:  message,
+				Source:   key,
+				Severity: "critical",
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown receiver type %q", receiver.Type)
+	}
+}
@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+func newTestServer(t *testing.T, status int, bodies *[]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("couldn't read request body: %v", err)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(b, &body); err != nil {
+			t.Errorf("request body isn't JSON: %v", err)
+		}
+		*bodies = append(*bodies, body)
+		w.WriteHeader(status)
+	}))
+}
+
+func TestNotify(t *testing.T) {
+	alert := Alert{
+		Namespace:          "default",
+		Name:               "foo",
+		AverageHealthiness: 0.5,
+		Time:               time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	resolved := alert
+	resolved.Resolved = true
+
+	tt := []struct {
+		name     string
+		receiver Receiver
+		alert    Alert
+		field    string
+		expected interface{}
+	}{
+		{
+			name:     "webhook firing",
+			receiver: Receiver{Type: healthv1alpha1.AlertReceiverWebhook},
+			alert:    alert,
+			field:    "status",
+			expected: "firing",
+		},
+		{
+			name:     "webhook resolved",
+			receiver: Receiver{Type: healthv1alpha1.AlertReceiverWebhook},
+			alert:    resolved,
+			field:    "status",
+			expected: "resolved",
+		},
+		{
+			name:     "slack default template",
+			receiver: Receiver{Type: healthv1alpha1.AlertReceiverSlack},
+			alert:    alert,
+			field:    "text",
+			expected: "HealthCheck default/foo is unhealthy, average healthiness 0.50",
+		},
+		{
+			name:     "slack custom template",
+			receiver: Receiver{Type: healthv1alpha1.AlertReceiverSlack, Template: "{{.Name}} resolved: {{.Resolved}}"},
+			alert:    resolved,
+			field:    "text",
+			expected: "foo resolved: true",
+		},
+		{
+			name:     "pagerduty resolve",
+			receiver: Receiver{Type: healthv1alpha1.AlertReceiverPagerDuty, RoutingKey: "key"},
+			alert:    resolved,
+			field:    "event_action",
+			expected: "resolve",
+		},
+		{
+			name:     "pagerduty dedup key",
+			receiver: Receiver{Type: healthv1alpha1.AlertReceiverPagerDuty, RoutingKey: "key"},
+			alert:    alert,
+			field:    "dedup_key",
+			expected: "default/foo",
+		},
+	}
+
+	for _, tc := range tt {
+		var bodies []map[string]interface{}
+		server := newTestServer(t, http.StatusAccepted, &bodies)
+		tc.receiver.URL = server.URL
+		if err := NewHTTPNotifier().Notify(tc.receiver, tc.alert); err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		server.Close()
+		if len(bodies) != 1 {
+			t.Errorf("%s: expected 1 request but got %d", tc.name, len(bodies))
+			continue
+		}
+		if value := bodies[0][tc.field]; value != tc.expected {
+			t.Errorf("%s: expected %s to be %v but got %v", tc.name, tc.field, tc.expected, value)
+		}
+	}
+}
+
+func TestNotifyError(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := newTestServer(t, http.StatusInternalServerError, &bodies)
+	defer server.Close()
+
+	receiver := Receiver{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: server.URL}
+	if err := NewHTTPNotifier().Notify(receiver, Alert{}); err == nil {
+		t.Errorf("expected an error from a failing receiver")
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tt := []struct {
+		url   string
+		valid bool
+	}{
+		{url: "https://hooks.example.com/alert", valid: true},
+		{url: "http://alerts.monitoring.svc:8080/", valid: true},
+		{url: "ftp://example.com/hook"},
+		{url: "file:///etc/passwd"},
+		{url: "example.com/hook"},
+		{url: "http://"},
+	}
+
+	for _, tc := range tt {
+		if err := ValidateURL(tc.url); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid=%v but got error %v", tc.url, tc.valid, err)
+		}
+	}
+}
+
+func TestNotifyRefusesLinkLocal(t *testing.T) {
+	receiver := Receiver{Name: "metadata", Type: healthv1alpha1.AlertReceiverWebhook, URL: "http://169.254.169.254/latest"}
+	if err := NewHTTPNotifier().Notify(receiver, Alert{}); err == nil {
+		t.Errorf("expected an error connecting to a link-local address")
+	}
+}
+
+func TestNotifyInvalidURL(t *testing.T) {
+	receiver := Receiver{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: "gopher://example.com"}
+	if err := NewHTTPNotifier().Notify(receiver, Alert{}); err == nil {
+		t.Errorf("expected an error for a non-HTTP URL")
+	}
+}
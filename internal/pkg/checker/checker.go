@@ -0,0 +1,100 @@
+// Package checker runs the built-in probes a HealthCheck can use instead of a
+// user supplied image. It is run inside check Pods by the hc-checker binary.
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Probe is the configuration passed to the checker. Exactly one probe should
+// be set.
+type Probe struct {
+	HTTP *healthv1alpha1.HTTPProbe `json:"http,omitempty"`
+	TCP  *healthv1alpha1.TCPProbe  `json:"tcp,omitempty"`
+	DNS  *healthv1alpha1.DNSProbe  `json:"dns,omitempty"`
+	// Steps are the steps of a scripted check.
+	Steps []healthv1alpha1.Step `json:"steps,omitempty"`
+}
+
+// Result is the outcome of running a probe. The checker writes it to the
+// Pod's termination message so the controller can read it back.
+type Result struct {
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+	// DurationMilliseconds is how long the probe took to run.
+	DurationMilliseconds int64 `json:"durationMilliseconds"`
+	// StatusCode is the response code of an HTTP probe.
+	StatusCode int `json:"statusCode,omitempty"`
+	// Answers are the records returned to a DNS probe.
+	Answers []string `json:"answers,omitempty"`
+	// FailedStep is the name of the step a scripted check failed at.
+	FailedStep string `json:"failedStep,omitempty"`
+	// Steps are the results of a scripted check's steps, up to the first
+	// that failed.
+	Steps []healthv1alpha1.StepResult `json:"steps,omitempty"`
+}
+
+// ProbeFromSpec returns the built-in probe configured by a HealthCheck spec,
+// or nil if the spec runs a user supplied image.
+func ProbeFromSpec(spec healthv1alpha1.HealthCheckSpec) *Probe {
+	if spec.HTTP == nil && spec.TCP == nil && spec.DNS == nil && len(spec.Steps) == 0 {
+		return nil
+	}
+	return &Probe{HTTP: spec.HTTP, TCP: spec.TCP, DNS: spec.DNS, Steps: spec.Steps}
+}
+
+// EnvVars returns the environment variables the checker needs to run the
+// probe, which pass it the values of headers read from Secrets.
+func (p Probe) EnvVars() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if p.HTTP != nil {
+		env = append(env, headerEnvVars(p.HTTP, headerEnvPrefix(-1))...)
+	}
+	for i := range p.Steps {
+		env = append(env, headerEnvVars(&p.Steps[i].HTTP, headerEnvPrefix(i))...)
+	}
+	return env
+}
+
+// Encode returns the probe as a string that can be passed to the checker as
+// an argument.
+func (p Probe) Encode() string {
+	// Probes are only made up of types that always marshal successfully.
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+// DecodeProbe parses a probe encoded with Encode.
+func DecodeProbe(s string) (Probe, error) {
+	var p Probe
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return p, fmt.Errorf("couldn't decode probe: %s", err.Error())
+	}
+	return p, nil
+}
+
+// Run runs the probe and returns its result.
+func Run(ctx context.Context, p Probe) Result {
+	start := time.Now()
+	var result Result
+	switch {
+	case p.HTTP != nil:
+		result = checkHTTP(ctx, p.HTTP)
+	case p.TCP != nil:
+		result = checkTCP(ctx, p.TCP)
+	case p.DNS != nil:
+		result = checkDNS(ctx, p.DNS)
+	case len(p.Steps) > 0:
+		result = runSteps(ctx, p.Steps)
+	default:
+		result = Result{Message: "no probe configured"}
+	}
+	result.DurationMilliseconds = milliseconds(time.Since(start))
+	return result
+}
@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+const defaultRecordType = "A"
+
+// checkDNS looks up the probe's name and checks the answers contain every
+// expected answer.
+func checkDNS(ctx context.Context, probe *healthv1alpha1.DNSProbe) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout(probe.TimeoutSeconds))
+	defer cancel()
+
+	resolver := &net.Resolver{PreferGo: true}
+	if probe.Resolver != "" {
+		resolver.Dial = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, probe.Resolver)
+		}
+	}
+
+	recordType := strings.ToUpper(probe.RecordType)
+	if recordType == "" {
+		recordType = defaultRecordType
+	}
+	answers, err := lookup(ctx, resolver, recordType, probe.Name)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("%s lookup of %s failed: %s", recordType, probe.Name, err.Error())}
+	}
+
+	result := Result{Answers: answers}
+	if len(answers) == 0 {
+		result.Message = fmt.Sprintf("%s lookup of %s returned no answers", recordType, probe.Name)
+		return result
+	}
+	for _, expected := range probe.ExpectedAnswers {
+		if !containsAnswer(answers, expected) {
+			result.Message = fmt.Sprintf("%s lookup of %s didn't return %q", recordType, probe.Name, expected)
+			return result
+		}
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("%s lookup of %s returned %s", recordType, probe.Name, strings.Join(answers, ", "))
+	return result
+}
+
+// lookup returns the answers to a query for the given record type, formatted
+// as strings.
+func lookup(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var answers []string
+	switch recordType {
+	case "A", "AAAA":
+		addrs, err := resolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if isIPv4 := addr.IP.To4() != nil; isIPv4 == (recordType == "A") {
+				answers = append(answers, addr.IP.String())
+			}
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, mx.Host)
+		}
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			answers = append(answers, ns.Host)
+		}
+	case "SRV":
+		_, srvs, err := resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range srvs {
+			answers = append(answers, net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))))
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, txts...)
+	default:
+		return nil, fmt.Errorf("unsupported record type %q", recordType)
+	}
+	return answers, nil
+}
+
+// containsAnswer returns true if the expected answer is in answers, ignoring
+// case and trailing dots on names.
+func containsAnswer(answers []string, expected string) bool {
+	normalise := func(s string) string {
+		return strings.ToLower(strings.TrimSuffix(s, "."))
+	}
+	for _, answer := range answers {
+		if normalise(answer) == normalise(expected) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package checker
+
+import (
+	"context"
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+func TestCheckDNS(t *testing.T) {
+	tt := []struct {
+		name   string
+		probe  healthv1alpha1.DNSProbe
+		passed bool
+	}{
+		{
+			name:   "any_answer",
+			probe:  healthv1alpha1.DNSProbe{Name: "localhost"},
+			passed: true,
+		},
+		{
+			name:   "expected_answer",
+			probe:  healthv1alpha1.DNSProbe{Name: "localhost", RecordType: "a", ExpectedAnswers: []string{"127.0.0.1"}},
+			passed: true,
+		},
+		{
+			name:  "missing_answer",
+			probe: healthv1alpha1.DNSProbe{Name: "localhost", ExpectedAnswers: []string{"10.0.0.1"}},
+		},
+		{
+			name:  "unsupported_record_type",
+			probe: healthv1alpha1.DNSProbe{Name: "localhost", RecordType: "SOA"},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			result := Run(context.Background(), Probe{DNS: &tc.probe})
+			if result.Passed != tc.passed {
+				t.Errorf("expected passed to be %t but got %t: %s", tc.passed, result.Passed, result.Message)
+			}
+		})
+	}
+}
+
+func TestContainsAnswer(t *testing.T) {
+	answers := []string{"Mail.Example.com.", "10.0.0.1"}
+	if !containsAnswer(answers, "mail.example.com") {
+		t.Errorf("expected answers to contain mail.example.com")
+	}
+	if containsAnswer(answers, "10.0.0.2") {
+		t.Errorf("expected answers not to contain 10.0.0.2")
+	}
+}
@@ -0,0 +1,221 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultHTTPMethod  = http.MethodGet
+	defaultTimeout     = 10 * time.Second
+	maxHTTPBodyToMatch = 1 << 20
+)
+
+// httpResponse is the part of a response that a scripted check's steps read
+// values from.
+type httpResponse struct {
+	header http.Header
+	body   []byte
+}
+
+// checkHTTP makes the probe's request and checks the response against its
+// expectations.
+func checkHTTP(ctx context.Context, probe *healthv1alpha1.HTTPProbe) Result {
+	resolved, err := resolveHeadersFrom(*probe, headerEnvPrefix(-1))
+	if err != nil {
+		return Result{Message: err.Error()}
+	}
+	result, _ := requestHTTP(ctx, &resolved, false)
+	return result
+}
+
+// headerEnvPrefix is the start of the names of the environment variables
+// that hold the values of a probe's headersFrom. step is the index of a
+// scripted check's step, or -1 for an HTTP probe.
+func headerEnvPrefix(step int) string {
+	if step < 0 {
+		return "HC_HEADER_"
+	}
+	return "HC_STEP_" + strconv.Itoa(step) + "_HEADER_"
+}
+
+// headerEnvVars returns the environment variables that pass the values of a
+// probe's headersFrom to the checker.
+func headerEnvVars(probe *healthv1alpha1.HTTPProbe, prefix string) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for i, header := range probe.HeadersFrom {
+		env = append(env, corev1.EnvVar{
+			Name:      prefix + strconv.Itoa(i),
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: header.ValueFrom.SecretKeyRef.DeepCopy()},
+		})
+	}
+	return env
+}
+
+// resolveHeadersFrom returns a copy of the probe with the values of its
+// headersFrom, read from the environment, added to its headers.
+func resolveHeadersFrom(probe healthv1alpha1.HTTPProbe, prefix string) (healthv1alpha1.HTTPProbe, error) {
+	if len(probe.HeadersFrom) == 0 {
+		return probe, nil
+	}
+	resolved := *probe.DeepCopy()
+	if resolved.Headers == nil {
+		resolved.Headers = map[string]string{}
+	}
+	for i, header := range probe.HeadersFrom {
+		value, ok := os.LookupEnv(prefix + strconv.Itoa(i))
+		if !ok {
+			return resolved, fmt.Errorf("header %s: environment variable %s%d isn't set", header.Name, prefix, i)
+		}
+		for name := range resolved.Headers {
+			if strings.EqualFold(name, header.Name) {
+				delete(resolved.Headers, name)
+			}
+		}
+		resolved.Headers[header.Name] = value
+	}
+	return resolved, nil
+}
+
+// ValidateHeadersFrom checks that each of a probe's headersFrom has a name
+// and selects a key of a Secret.
+func ValidateHeadersFrom(headers []healthv1alpha1.HTTPHeaderSource) error {
+	for i, header := range headers {
+		if header.Name == "" {
+			return fmt.Errorf("headersFrom %d must have a name", i)
+		}
+		ref := header.ValueFrom.SecretKeyRef
+		if ref == nil || ref.Name == "" || ref.Key == "" {
+			return fmt.Errorf("header %s must have a valueFrom.secretKeyRef with a name and key", header.Name)
+		}
+	}
+	return nil
+}
+
+// followRedirects returns true if the probe follows redirects. It does
+// unless it's disabled, or a redirect code is expected, which could never
+// be seen if redirects were followed.
+func followRedirects(probe *healthv1alpha1.HTTPProbe) bool {
+	if probe.FollowRedirects != nil {
+		return *probe.FollowRedirects
+	}
+	for _, code := range probe.ExpectedStatusCodes {
+		if code >= http.StatusMultipleChoices && code < http.StatusBadRequest {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHTTP makes the probe's request and checks the response against its
+// expectations. The response is returned whenever one is received, with its
+// body read if the probe has a body regex or readBody is true.
+func requestHTTP(ctx context.Context, probe *healthv1alpha1.HTTPProbe, readBody bool) (Result, *httpResponse) {
+	var bodyRegex *regexp.Regexp
+	if probe.BodyRegex != "" {
+		var err error
+		if bodyRegex, err = regexp.Compile(probe.BodyRegex); err != nil {
+			return Result{Message: fmt.Sprintf("invalid body regex: %s", err.Error())}, nil
+		}
+	}
+
+	method := probe.Method
+	if method == "" {
+		method = defaultHTTPMethod
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout(probe.TimeoutSeconds))
+	defer cancel()
+	var reqBody io.Reader
+	if probe.Body != "" {
+		reqBody = strings.NewReader(probe.Body)
+	}
+	req, err := http.NewRequest(method, probe.URL, reqBody)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("invalid request: %s", err.Error())}, nil
+	}
+	req = req.WithContext(ctx)
+	for name, value := range probe.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: probe.InsecureSkipVerify},
+		},
+	}
+	if !followRedirects(probe) {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("%s %s failed: %s", method, probe.URL, err.Error())}, nil
+	}
+	defer resp.Body.Close()
+
+	result := Result{StatusCode: resp.StatusCode}
+	response := &httpResponse{header: resp.Header}
+	if !expectedStatusCode(probe.ExpectedStatusCodes, resp.StatusCode) {
+		result.Message = fmt.Sprintf("%s %s returned unexpected status %d", method, probe.URL, resp.StatusCode)
+		return result, response
+	}
+
+	if bodyRegex != nil || readBody {
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxHTTPBodyToMatch))
+		if err != nil {
+			result.Message = fmt.Sprintf("couldn't read response body: %s", err.Error())
+			return result, response
+		}
+		response.body = body
+		if bodyRegex != nil && !bodyRegex.Match(body) {
+			result.Message = fmt.Sprintf("%s %s response body didn't match %q", method, probe.URL, probe.BodyRegex)
+			return result, response
+		}
+	}
+
+	result.Passed = true
+	result.Message = fmt.Sprintf("%s %s returned status %d", method, probe.URL, resp.StatusCode)
+	return result, response
+}
+
+// expectedStatusCode returns true if the code is one of the expected codes,
+// or is a success or redirect code if none are given.
+func expectedStatusCode(expected []int32, code int) bool {
+	if len(expected) == 0 {
+		return code >= http.StatusOK && code < http.StatusBadRequest
+	}
+	for _, e := range expected {
+		if int(e) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// timeout returns the probe timeout for the given number of seconds, or the
+// default if it isn't set.
+func timeout(seconds int32) time.Duration {
+	if seconds <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(seconds) * time.Second
+}
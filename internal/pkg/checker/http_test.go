@@ -0,0 +1,182 @@
+package checker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func newTestServer(tls bool) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"status": "ok"}`))
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/teapot":
+			w.WriteHeader(http.StatusTeapot)
+		case "/auth":
+			if r.Header.Get("Authorization") != "Bearer token" || r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	if tls {
+		return httptest.NewTLSServer(handler)
+	}
+	return httptest.NewServer(handler)
+}
+
+func TestCheckHTTP(t *testing.T) {
+	server := newTestServer(false)
+	defer server.Close()
+	tlsServer := newTestServer(true)
+	defer tlsServer.Close()
+	yes, no := true, false
+
+	tt := []struct {
+		name       string
+		probe      healthv1alpha1.HTTPProbe
+		passed     bool
+		statusCode int
+	}{
+		{
+			name:       "default_status_codes_pass",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/ok"},
+			passed:     true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "default_status_codes_fail",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/error"},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "expected_status_code",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/teapot", ExpectedStatusCodes: []int32{418}},
+			passed:     true,
+			statusCode: http.StatusTeapot,
+		},
+		{
+			name:       "unexpected_status_code",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/ok", ExpectedStatusCodes: []int32{204}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "body_regex_matches",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/ok", BodyRegex: `"status":\s*"ok"`},
+			passed:     true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "body_regex_doesnt_match",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/ok", BodyRegex: `"status":\s*"degraded"`},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "invalid_body_regex",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/ok", BodyRegex: `(`},
+			statusCode: 0,
+		},
+		{
+			name: "method_and_headers",
+			probe: healthv1alpha1.HTTPProbe{
+				URL:     server.URL + "/auth",
+				Method:  http.MethodPost,
+				Headers: map[string]string{"Authorization": "Bearer token"},
+			},
+			passed:     true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "redirect_followed",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/redirect"},
+			passed:     true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "expected_redirect_not_followed",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/redirect", ExpectedStatusCodes: []int32{302}},
+			passed:     true,
+			statusCode: http.StatusFound,
+		},
+		{
+			name:       "redirects_disabled",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/redirect", ExpectedStatusCodes: []int32{200}, FollowRedirects: &no},
+			statusCode: http.StatusFound,
+		},
+		{
+			name:       "redirects_enabled",
+			probe:      healthv1alpha1.HTTPProbe{URL: server.URL + "/redirect", ExpectedStatusCodes: []int32{200, 302}, FollowRedirects: &yes},
+			passed:     true,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "tls_verify_fails",
+			probe:      healthv1alpha1.HTTPProbe{URL: tlsServer.URL + "/ok"},
+			statusCode: 0,
+		},
+		{
+			name:       "tls_verify_skipped",
+			probe:      healthv1alpha1.HTTPProbe{URL: tlsServer.URL + "/ok", InsecureSkipVerify: true},
+			passed:     true,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			result := Run(context.Background(), Probe{HTTP: &tc.probe})
+			if result.Passed != tc.passed {
+				t.Errorf("expected passed to be %t but got %t: %s", tc.passed, result.Passed, result.Message)
+			}
+			if result.StatusCode != tc.statusCode {
+				t.Errorf("expected status code %d but got %d", tc.statusCode, result.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCheckHTTPHeadersFrom(t *testing.T) {
+	server := newTestServer(false)
+	defer server.Close()
+
+	probe := healthv1alpha1.HTTPProbe{
+		URL:     server.URL + "/auth",
+		Method:  http.MethodPost,
+		Headers: map[string]string{"authorization": "Bearer stale"},
+		HeadersFrom: []healthv1alpha1.HTTPHeaderSource{{
+			Name: "Authorization",
+			ValueFrom: healthv1alpha1.HTTPHeaderValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "api"},
+				Key:                  "authorization",
+			}},
+		}},
+	}
+	expectedEnv := []corev1.EnvVar{{
+		Name:      "HC_HEADER_0",
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: probe.HeadersFrom[0].ValueFrom.SecretKeyRef},
+	}}
+	if env := (Probe{HTTP: &probe}).EnvVars(); !reflect.DeepEqual(env, expectedEnv) {
+		t.Errorf("expected env %+v but got %+v", expectedEnv, env)
+	}
+
+	if result := Run(context.Background(), Probe{HTTP: &probe}); result.Passed {
+		t.Errorf("expected the probe to fail without its environment variable")
+	}
+
+	os.Setenv("HC_HEADER_0", "Bearer token")
+	defer os.Unsetenv("HC_HEADER_0")
+	if result := Run(context.Background(), Probe{HTTP: &probe}); !result.Passed {
+		t.Errorf("expected the header to be read from the environment: %s", result.Message)
+	}
+}
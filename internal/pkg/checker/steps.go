@@ -0,0 +1,262 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var (
+	// variablePattern matches a reference to a captured variable, such as
+	// ${token}.
+	variablePattern  = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	variableNameRule = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// runSteps makes each step's request in order, stopping at the first step
+// that fails.
+func runSteps(ctx context.Context, steps []healthv1alpha1.Step) Result {
+	vars := map[string]string{}
+	result := Result{}
+	for i, step := range steps {
+		start := time.Now()
+		stepResult := runStep(ctx, i, step, vars)
+		stepResult.DurationMilliseconds = milliseconds(time.Since(start))
+		result.Steps = append(result.Steps, stepResult)
+		result.StatusCode = int(stepResult.StatusCode)
+		if !stepResult.Passed {
+			result.FailedStep = step.Name
+			result.Message = fmt.Sprintf("step %s failed: %s", step.Name, stepResult.Message)
+			return result
+		}
+	}
+	result.Passed = true
+	result.Message = fmt.Sprintf("all %d steps passed", len(steps))
+	return result
+}
+
+// runStep makes the request of the step at index i with the variables
+// captured so far, checks its response, and adds the values it captures to
+// vars.
+func runStep(ctx context.Context, i int, step healthv1alpha1.Step, vars map[string]string) healthv1alpha1.StepResult {
+	stepResult := healthv1alpha1.StepResult{Name: step.Name}
+	probe, err := expandProbe(step.HTTP, vars)
+	if err != nil {
+		stepResult.Message = err.Error()
+		return stepResult
+	}
+	// Secret values are added after expansion, so they're sent as they are.
+	if probe, err = resolveHeadersFrom(probe, headerEnvPrefix(i)); err != nil {
+		stepResult.Message = err.Error()
+		return stepResult
+	}
+
+	result, response := requestHTTP(ctx, &probe, true)
+	stepResult.Message = result.Message
+	stepResult.StatusCode = int32(result.StatusCode)
+	if !result.Passed {
+		return stepResult
+	}
+	for _, assertion := range step.Assertions {
+		if err := assert(response, assertion); err != nil {
+			stepResult.Message = err.Error()
+			return stepResult
+		}
+	}
+	for _, capture := range step.Capture {
+		value, err := responseValue(response, capture.ResponseValue)
+		if err != nil {
+			stepResult.Message = fmt.Sprintf("couldn't capture %s: %s", capture.Name, err.Error())
+			return stepResult
+		}
+		vars[capture.Name] = value
+	}
+	stepResult.Passed = true
+	return stepResult
+}
+
+// expandProbe returns a copy of the probe with variables substituted into its
+// URL, header values and body.
+func expandProbe(probe healthv1alpha1.HTTPProbe, vars map[string]string) (healthv1alpha1.HTTPProbe, error) {
+	expanded := *probe.DeepCopy()
+	var missing []string
+	expand := func(s string) string {
+		return variablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			name := variablePattern.FindStringSubmatch(ref)[1]
+			value, ok := vars[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+	expanded.URL = expand(expanded.URL)
+	expanded.Body = expand(expanded.Body)
+	for name, value := range expanded.Headers {
+		expanded.Headers[name] = expand(value)
+	}
+	if len(missing) > 0 {
+		return expanded, fmt.Errorf("variable %s hasn't been captured", missing[0])
+	}
+	return expanded, nil
+}
+
+// assert checks a value from the response.
+func assert(response *httpResponse, assertion healthv1alpha1.StepAssertion) error {
+	value, err := responseValue(response, assertion.ResponseValue)
+	if err != nil {
+		return err
+	}
+	source := describeValue(assertion.ResponseValue)
+	if assertion.Equals != "" && value != assertion.Equals {
+		return fmt.Errorf("%s is %q, expected %q", source, value, assertion.Equals)
+	}
+	if assertion.Matches != "" {
+		re, err := regexp.Compile(assertion.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid regex for %s: %s", source, err.Error())
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s is %q, which doesn't match %q", source, value, assertion.Matches)
+		}
+	}
+	return nil
+}
+
+// responseValue returns the value selected from the response.
+func responseValue(response *httpResponse, selector healthv1alpha1.ResponseValue) (string, error) {
+	if selector.Header != "" {
+		values, ok := response.header[http.CanonicalHeaderKey(selector.Header)]
+		if !ok || len(values) == 0 {
+			return "", fmt.Errorf("response has no %s header", selector.Header)
+		}
+		return values[0], nil
+	}
+
+	path, err := parseJSONPath(selector.JSONPath)
+	if err != nil {
+		return "", err
+	}
+	var body interface{}
+	if err := json.Unmarshal(response.body, &body); err != nil {
+		return "", fmt.Errorf("response body isn't JSON: %s", err.Error())
+	}
+	results, err := path.FindResults(body)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", describeValue(selector), err.Error())
+	}
+	var values []reflect.Value
+	for _, result := range results {
+		values = append(values, result...)
+	}
+	if len(values) != 1 {
+		return "", fmt.Errorf("%s selected %d values, expected 1", describeValue(selector), len(values))
+	}
+	value := values[0].Interface()
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("couldn't encode %s: %s", describeValue(selector), err.Error())
+	}
+	return string(b), nil
+}
+
+// parseJSONPath parses a JSONPath expression, with or without the
+// surrounding braces.
+func parseJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	path := jsonpath.New("value")
+	if err := path.Parse(expr); err != nil {
+		return nil, fmt.Errorf("invalid jsonPath %q: %s", expr, err.Error())
+	}
+	return path, nil
+}
+
+func describeValue(selector healthv1alpha1.ResponseValue) string {
+	if selector.Header != "" {
+		return fmt.Sprintf("header %s", selector.Header)
+	}
+	return fmt.Sprintf("jsonPath %s", selector.JSONPath)
+}
+
+// ValidateSteps checks that a scripted check's steps can be run, and that
+// each variable they use is captured by an earlier step.
+func ValidateSteps(steps []healthv1alpha1.Step) error {
+	names := map[string]bool{}
+	captured := map[string]bool{}
+	for i, step := range steps {
+		if step.Name == "" {
+			return fmt.Errorf("step %d must have a name", i)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step name %s is used more than once", step.Name)
+		}
+		names[step.Name] = true
+		if step.HTTP.URL == "" {
+			return fmt.Errorf("step %s must have a url", step.Name)
+		}
+		if err := ValidateHeadersFrom(step.HTTP.HeadersFrom); err != nil {
+			return fmt.Errorf("step %s: %s", step.Name, err.Error())
+		}
+
+		refs := []string{step.HTTP.URL, step.HTTP.Body}
+		for _, value := range step.HTTP.Headers {
+			refs = append(refs, value)
+		}
+		for _, ref := range refs {
+			for _, match := range variablePattern.FindAllStringSubmatch(ref, -1) {
+				if !captured[match[1]] {
+					return fmt.Errorf("step %s uses variable %s, which isn't captured by an earlier step", step.Name, match[1])
+				}
+			}
+		}
+
+		for _, assertion := range step.Assertions {
+			if err := validateResponseValue(assertion.ResponseValue); err != nil {
+				return fmt.Errorf("step %s has an invalid assertion: %s", step.Name, err.Error())
+			}
+			if assertion.Matches != "" {
+				if _, err := regexp.Compile(assertion.Matches); err != nil {
+					return fmt.Errorf("step %s has an invalid assertion: %s", step.Name, err.Error())
+				}
+			}
+		}
+		for _, capture := range step.Capture {
+			if !variableNameRule.MatchString(capture.Name) {
+				return fmt.Errorf("step %s captures %q, but variable names must be letters, digits and underscores", step.Name, capture.Name)
+			}
+			if err := validateResponseValue(capture.ResponseValue); err != nil {
+				return fmt.Errorf("step %s has an invalid capture %s: %s", step.Name, capture.Name, err.Error())
+			}
+			captured[capture.Name] = true
+		}
+	}
+	return nil
+}
+
+func validateResponseValue(selector healthv1alpha1.ResponseValue) error {
+	if (selector.JSONPath == "") == (selector.Header == "") {
+		return fmt.Errorf("exactly one of jsonPath or header must be set")
+	}
+	if selector.JSONPath != "" {
+		_, err := parseJSONPath(selector.JSONPath)
+		return err
+	}
+	return nil
+}
+
+func milliseconds(d time.Duration) int64 {
+	return d.Nanoseconds() / int64(time.Millisecond)
+}
@@ -0,0 +1,205 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+// newJourneyServer returns a server where users log in to get a token, and
+// use it to fetch their profile.
+func newJourneyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			var creds map[string]string
+			body, _ := ioutil.ReadAll(r.Body)
+			if r.Method != http.MethodPost || json.Unmarshal(body, &creds) != nil || creds["user"] != "alice" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("X-Session", "s1")
+			w.Write([]byte(`{"token": "abc", "expires": 3600}`))
+		case "/users/alice":
+			if r.Header.Get("Authorization") != "Bearer abc" || r.URL.Query().Get("session") != "s1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"user": {"name": "Alice", "roles": ["admin"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func journeySteps(url string) []healthv1alpha1.Step {
+	return []healthv1alpha1.Step{
+		{
+			Name: "login",
+			HTTP: healthv1alpha1.HTTPProbe{
+				URL:    url + "/login",
+				Method: http.MethodPost,
+				Body:   `{"user": "alice"}`,
+			},
+			Capture: []healthv1alpha1.StepCapture{
+				{Name: "token", ResponseValue: healthv1alpha1.ResponseValue{JSONPath: ".token"}},
+				{Name: "session", ResponseValue: healthv1alpha1.ResponseValue{Header: "x-session"}},
+			},
+		},
+		{
+			Name: "profile",
+			HTTP: healthv1alpha1.HTTPProbe{
+				URL:     url + "/users/alice?session=${session}",
+				Headers: map[string]string{"Authorization": "Bearer ${token}"},
+			},
+			Assertions: []healthv1alpha1.StepAssertion{
+				{ResponseValue: healthv1alpha1.ResponseValue{JSONPath: "{.user.name}"}, Equals: "Alice"},
+				{ResponseValue: healthv1alpha1.ResponseValue{JSONPath: ".user.roles"}, Matches: "admin"},
+			},
+		},
+	}
+}
+
+func TestRunSteps(t *testing.T) {
+	server := newJourneyServer()
+	defer server.Close()
+
+	steps := journeySteps(server.URL)
+	if err := ValidateSteps(steps); err != nil {
+		t.Fatalf("unexpected error validating steps: %v", err)
+	}
+	result := Run(context.Background(), Probe{Steps: steps})
+	if !result.Passed {
+		t.Fatalf("expected the steps to pass, got %+v", result)
+	}
+	if len(result.Steps) != 2 || !result.Steps[0].Passed || !result.Steps[1].Passed {
+		t.Errorf("expected a passed result for each step, got %+v", result.Steps)
+	}
+	if result.Steps[1].StatusCode != http.StatusOK {
+		t.Errorf("expected the profile step to record status 200, got %d", result.Steps[1].StatusCode)
+	}
+}
+
+func TestRunStepsFailure(t *testing.T) {
+	server := newJourneyServer()
+	defer server.Close()
+
+	tt := []struct {
+		name       string
+		change     func(steps []healthv1alpha1.Step)
+		failedStep string
+		message    string
+	}{
+		{
+			name:       "request_fails",
+			change:     func(steps []healthv1alpha1.Step) { steps[0].HTTP.Body = `{"user": "mallory"}` },
+			failedStep: "login",
+			message:    "step login failed: POST " + server.URL + "/login returned unexpected status 401",
+		},
+		{
+			name: "capture_missing",
+			change: func(steps []healthv1alpha1.Step) {
+				steps[0].Capture[0].JSONPath = ".missing"
+			},
+			failedStep: "login",
+			message:    "step login failed: couldn't capture token: jsonPath .missing: missing is not found",
+		},
+		{
+			name: "assertion_fails",
+			change: func(steps []healthv1alpha1.Step) {
+				steps[1].Assertions[0].Equals = "Bob"
+			},
+			failedStep: "profile",
+			message:    `step profile failed: jsonPath {.user.name} is "Alice", expected "Bob"`,
+		},
+		{
+			name: "header_missing",
+			change: func(steps []healthv1alpha1.Step) {
+				steps[1].Assertions = append(steps[1].Assertions, healthv1alpha1.StepAssertion{
+					ResponseValue: healthv1alpha1.ResponseValue{Header: "X-Request-Id"},
+				})
+			},
+			failedStep: "profile",
+			message:    "step profile failed: response has no X-Request-Id header",
+		},
+	}
+	for _, tc := range tt {
+		steps := journeySteps(server.URL)
+		tc.change(steps)
+		result := Run(context.Background(), Probe{Steps: steps})
+		if result.Passed {
+			t.Errorf("%s: expected the steps to fail", tc.name)
+			continue
+		}
+		if result.FailedStep != tc.failedStep {
+			t.Errorf("%s: expected step %s to fail but got %q", tc.name, tc.failedStep, result.FailedStep)
+		}
+		if result.Message != tc.message {
+			t.Errorf("%s: expected message %q but got %q", tc.name, tc.message, result.Message)
+		}
+		if last := result.Steps[len(result.Steps)-1]; last.Name != tc.failedStep || last.Passed {
+			t.Errorf("%s: expected the results to stop at the failed step, got %+v", tc.name, result.Steps)
+		}
+	}
+}
+
+func TestValidateSteps(t *testing.T) {
+	step := func(name, url string) healthv1alpha1.Step {
+		return healthv1alpha1.Step{Name: name, HTTP: healthv1alpha1.HTTPProbe{URL: url}}
+	}
+	capture := func(s healthv1alpha1.Step, name string, value healthv1alpha1.ResponseValue) healthv1alpha1.Step {
+		s.Capture = append(s.Capture, healthv1alpha1.StepCapture{Name: name, ResponseValue: value})
+		return s
+	}
+	token := healthv1alpha1.ResponseValue{JSONPath: ".token"}
+
+	tt := []struct {
+		name  string
+		steps []healthv1alpha1.Step
+		valid bool
+	}{
+		{name: "journey", steps: journeySteps("http://example.com"), valid: true},
+		{name: "no_name", steps: []healthv1alpha1.Step{step("", "http://example.com")}},
+		{name: "duplicate_name", steps: []healthv1alpha1.Step{step("a", "http://example.com"), step("a", "http://example.com")}},
+		{name: "no_url", steps: []healthv1alpha1.Step{step("a", "")}},
+		{name: "uncaptured_variable", steps: []healthv1alpha1.Step{step("a", "http://example.com/${token}")}},
+		{
+			name:  "variable_from_same_step",
+			steps: []healthv1alpha1.Step{capture(step("a", "http://example.com/${token}"), "token", token)},
+		},
+		{name: "bad_variable_name", steps: []healthv1alpha1.Step{capture(step("a", "http://example.com"), "my-token", token)}},
+		{
+			name:  "both_sources",
+			steps: []healthv1alpha1.Step{capture(step("a", "http://example.com"), "token", healthv1alpha1.ResponseValue{JSONPath: ".token", Header: "X-Token"})},
+		},
+		{
+			name:  "bad_json_path",
+			steps: []healthv1alpha1.Step{capture(step("a", "http://example.com"), "token", healthv1alpha1.ResponseValue{JSONPath: "{.token"})},
+		},
+		{
+			name: "header_without_secret",
+			steps: []healthv1alpha1.Step{{
+				Name: "a",
+				HTTP: healthv1alpha1.HTTPProbe{URL: "http://example.com", HeadersFrom: []healthv1alpha1.HTTPHeaderSource{{Name: "Authorization"}}},
+			}},
+		},
+		{
+			name: "bad_assertion_regex",
+			steps: []healthv1alpha1.Step{{
+				Name:       "a",
+				HTTP:       healthv1alpha1.HTTPProbe{URL: "http://example.com"},
+				Assertions: []healthv1alpha1.StepAssertion{{ResponseValue: token, Matches: "("}},
+			}},
+		},
+	}
+	for _, tc := range tt {
+		if err := ValidateSteps(tc.steps); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tc.name, tc.valid, err)
+		}
+	}
+}
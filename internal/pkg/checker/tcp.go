@@ -0,0 +1,55 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+const maxTCPBytesToMatch = 64 * 1024
+
+// checkTCP opens a connection to the probe's address, optionally exchanging
+// data over it.
+func checkTCP(ctx context.Context, probe *healthv1alpha1.TCPProbe) Result {
+	address := net.JoinHostPort(probe.Host, strconv.Itoa(int(probe.Port)))
+	deadline := time.Now().Add(timeout(probe.TimeoutSeconds))
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return Result{Message: fmt.Sprintf("couldn't connect to %s: %s", address, err.Error())}
+	}
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+
+	if probe.Send != "" {
+		if _, err := conn.Write([]byte(probe.Send)); err != nil {
+			return Result{Message: fmt.Sprintf("couldn't send to %s: %s", address, err.Error())}
+		}
+	}
+
+	if probe.Expect != "" {
+		expect := []byte(probe.Expect)
+		received := make([]byte, 0, len(expect))
+		buf := make([]byte, 4096)
+		for !bytes.Contains(received, expect) {
+			if len(received) >= maxTCPBytesToMatch {
+				return Result{Message: fmt.Sprintf("%s didn't send %q", address, probe.Expect)}
+			}
+			n, err := conn.Read(buf)
+			received = append(received, buf[:n]...)
+			if err != nil && !bytes.Contains(received, expect) {
+				return Result{Message: fmt.Sprintf("%s didn't send %q: %s", address, probe.Expect, err.Error())}
+			}
+		}
+	}
+
+	return Result{Passed: true, Message: fmt.Sprintf("connected to %s", address)}
+}
@@ -0,0 +1,82 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+// newEchoServer starts a TCP server that replies "PONG" to a "PING" line, and
+// returns its port.
+func newEchoServer(t *testing.T) (int32, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err == nil && line == "PING\n" {
+					conn.Write([]byte("PONG\n"))
+				}
+			}()
+		}
+	}()
+	return int32(listener.Addr().(*net.TCPAddr).Port), func() { listener.Close() }
+}
+
+func TestCheckTCP(t *testing.T) {
+	port, stop := newEchoServer(t)
+	defer stop()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("couldn't start listener: %v", err)
+	}
+	closedPort := int32(closed.Addr().(*net.TCPAddr).Port)
+	closed.Close()
+
+	tt := []struct {
+		name   string
+		probe  healthv1alpha1.TCPProbe
+		passed bool
+	}{
+		{
+			name:   "connects",
+			probe:  healthv1alpha1.TCPProbe{Host: "127.0.0.1", Port: port},
+			passed: true,
+		},
+		{
+			name:  "connection_refused",
+			probe: healthv1alpha1.TCPProbe{Host: "127.0.0.1", Port: closedPort},
+		},
+		{
+			name:   "send_and_expect",
+			probe:  healthv1alpha1.TCPProbe{Host: "127.0.0.1", Port: port, Send: "PING\n", Expect: "PONG"},
+			passed: true,
+		},
+		{
+			name:  "unexpected_reply",
+			probe: healthv1alpha1.TCPProbe{Host: "127.0.0.1", Port: port, Send: "HELLO\n", Expect: "PONG", TimeoutSeconds: 1},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			result := Run(context.Background(), Probe{TCP: &tc.probe})
+			if result.Passed != tc.passed {
+				t.Errorf("expected passed to be %t but got %t: %s", tc.passed, result.Passed, result.Message)
+			}
+		})
+	}
+}
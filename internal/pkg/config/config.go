@@ -0,0 +1,108 @@
+// Package config loads the controller's configuration, which can be set in a
+// versioned configuration file, environment variables and flags.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the version of the configuration file format.
+	APIVersion = "config.health.mbell.dev/v1alpha1"
+	// Kind is the kind of the configuration file.
+	Kind = "ControllerConfiguration"
+)
+
+// Config configures the controller.
+type Config struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Workers is the number of workers processing each workqueue.
+	Workers int `json:"workers"`
+	// ResyncPeriod is how often informers resync every object they watch.
+	ResyncPeriod metav1.Duration `json:"resyncPeriod"`
+	// CheckerImage is the image containing the hc-checker binary, used to run
+	// built-in probes.
+	CheckerImage string `json:"checkerImage"`
+	// DefaultCronPattern is the schedule of HealthChecks that set neither a
+	// frequency nor a cronPattern.
+	DefaultCronPattern string `json:"defaultCronPattern"`
+	// SuccessfulJobsHistoryLimit is the number of successful Jobs kept for
+	// HealthChecks that don't set a limit.
+	SuccessfulJobsHistoryLimit int32 `json:"successfulJobsHistoryLimit"`
+	// FailedJobsHistoryLimit is the number of failed Jobs kept for
+	// HealthChecks that don't set a limit.
+	FailedJobsHistoryLimit int32 `json:"failedJobsHistoryLimit"`
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() *Config {
+	return &Config{
+		TypeMeta:                   metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+		Workers:                    2,
+		ResyncPeriod:               metav1.Duration{Duration: 30 * time.Second},
+		CheckerImage:               "docker.pkg.github.com/mbellgb/healthcheck-controller/hc-controller:latest",
+		DefaultCronPattern:         "*/1 * * * *",
+		SuccessfulJobsHistoryLimit: 10,
+		FailedJobsHistoryLimit:     10,
+	}
+}
+
+// Load reads the configuration file at path. Settings missing from the file
+// keep their defaults, and unknown settings are an error.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := Default()
+	// The file must say which version it is.
+	cfg.TypeMeta = metav1.TypeMeta{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("couldn't decode %s: %s", path, err.Error())
+	}
+	if cfg.APIVersion != APIVersion || cfg.Kind != Kind {
+		return nil, fmt.Errorf("%s has apiVersion %q and kind %q, expected %q and %q", path, cfg.APIVersion, cfg.Kind, APIVersion, Kind)
+	}
+	return cfg, nil
+}
+
+// Validate returns an error describing every invalid setting.
+func (cfg *Config) Validate() error {
+	var errs field.ErrorList
+	if cfg.Workers < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("workers"), cfg.Workers, "must be at least 1"))
+	}
+	if cfg.ResyncPeriod.Duration < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("resyncPeriod"), cfg.ResyncPeriod.Duration.String(), "must not be negative"))
+	}
+	if cfg.CheckerImage == "" {
+		errs = append(errs, field.Required(field.NewPath("checkerImage"), ""))
+	}
+	if _, err := cron.ParseStandard(cfg.DefaultCronPattern); err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("defaultCronPattern"), cfg.DefaultCronPattern, err.Error()))
+	}
+	if cfg.SuccessfulJobsHistoryLimit < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("successfulJobsHistoryLimit"), cfg.SuccessfulJobsHistoryLimit, "must be at least 1"))
+	}
+	if cfg.FailedJobsHistoryLimit < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("failedJobsHistoryLimit"), cfg.FailedJobsHistoryLimit, "must be at least 1"))
+	}
+	return errs.ToAggregate()
+}
+
+// String returns the configuration as a configuration file.
+func (cfg *Config) String() string {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Sprintf("%#v", *cfg)
+	}
+	return string(data)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile writes a configuration file to a new temporary directory, and
+// returns its path.
+func writeFile(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatalf("couldn't create temporary directory: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("couldn't write configuration file: %v", err)
+	}
+	return path
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Errorf("expected the default configuration to be valid, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeFile(t, `
+apiVersion: config.health.mbell.dev/v1alpha1
+kind: ControllerConfiguration
+workers: 4
+resyncPeriod: 1m
+defaultCronPattern: "*/5 * * * *"
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := Default()
+	expected.Workers = 4
+	expected.ResyncPeriod.Duration = time.Minute
+	expected.DefaultCronPattern = "*/5 * * * *"
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected %+v but got %+v", expected, cfg)
+	}
+}
+
+func TestLoadExample(t *testing.T) {
+	cfg, err := Load("../../../artifacts/config/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("expected the example to match the defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		expected string
+	}{
+		{
+			name:     "unknown setting",
+			contents: "apiVersion: config.health.mbell.dev/v1alpha1\nkind: ControllerConfiguration\nworkerCount: 4\n",
+			expected: `unknown field "workerCount"`,
+		},
+		{
+			name:     "wrong version",
+			contents: "apiVersion: config.health.mbell.dev/v1beta1\nkind: ControllerConfiguration\n",
+			expected: `apiVersion "config.health.mbell.dev/v1beta1"`,
+		},
+		{
+			name:     "no kind",
+			contents: "apiVersion: config.health.mbell.dev/v1alpha1\n",
+			expected: `kind ""`,
+		},
+		{
+			name:     "bad duration",
+			contents: "apiVersion: config.health.mbell.dev/v1alpha1\nkind: ControllerConfiguration\nresyncPeriod: often\n",
+			expected: "couldn't decode",
+		},
+	}
+	for _, test := range tests {
+		path := writeFile(t, test.contents)
+		_, err := Load(path)
+		os.RemoveAll(filepath.Dir(path))
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.expected, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Workers = 0
+	cfg.ResyncPeriod.Duration = -time.Second
+	cfg.CheckerImage = ""
+	cfg.DefaultCronPattern = "every minute"
+	cfg.SuccessfulJobsHistoryLimit = 0
+	cfg.FailedJobsHistoryLimit = -1
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, field := range []string{"workers", "resyncPeriod", "checkerImage", "defaultCronPattern", "successfulJobsHistoryLimit", "failedJobsHistoryLimit"} {
+		if !strings.Contains(err.Error(), field+":") {
+			t.Errorf("expected the error to name %s, got %v", field, err)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	env := map[string]string{
+		"HC_CONTROLLER_WORKERS":              "3",
+		"HC_CONTROLLER_RESYNC_PERIOD":        "45s",
+		"HC_CONTROLLER_DEFAULT_CRON_PATTERN": "*/2 * * * *",
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := AddFlags(fs)
+	if err := fs.Parse([]string{"-workers=5", "-failed-jobs-history-limit=1"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cfg := Default()
+	cfg.CheckerImage = "hc-checker:file"
+	err := cfg.ApplyEnv(func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	flags.Apply(cfg)
+
+	expected := Default()
+	// The file is overridden by the environment, and the environment by flags.
+	expected.CheckerImage = "hc-checker:file"
+	expected.Workers = 5
+	expected.ResyncPeriod.Duration = 45 * time.Second
+	expected.DefaultCronPattern = "*/2 * * * *"
+	expected.FailedJobsHistoryLimit = 1
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected %+v but got %+v", expected, cfg)
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	err := Default().ApplyEnv(func(key string) (string, bool) {
+		return "lots", key == "HC_CONTROLLER_WORKERS"
+	})
+	if err == nil || !strings.Contains(err.Error(), "HC_CONTROLLER_WORKERS") {
+		t.Errorf("expected an error naming HC_CONTROLLER_WORKERS, got %v", err)
+	}
+}
+
+func TestFlagDefaults(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	AddFlags(fs)
+	if f := fs.Lookup("resync-period"); f == nil || f.DefValue != "30s" {
+		t.Errorf("expected -resync-period to default to 30s, got %+v", f)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// EnvPrefix prefixes the environment variable of every setting.
+const EnvPrefix = "HC_CONTROLLER_"
+
+// setting is a configuration setting that can be overridden by a flag and an
+// environment variable.
+type setting struct {
+	// name is the name of the flag, and env the environment variable.
+	name  string
+	env   string
+	usage string
+	// value returns the setting in cfg.
+	value func(cfg *Config) flag.Value
+}
+
+var settings = []setting{
+	{
+		name:  "workers",
+		env:   EnvPrefix + "WORKERS",
+		usage: "Number of workers processing each workqueue.",
+		value: func(cfg *Config) flag.Value { return (*intValue)(&cfg.Workers) },
+	},
+	{
+		name:  "resync-period",
+		env:   EnvPrefix + "RESYNC_PERIOD",
+		usage: "How often informers resync every object they watch.",
+		value: func(cfg *Config) flag.Value { return (*durationValue)(&cfg.ResyncPeriod.Duration) },
+	},
+	{
+		name:  "checker-image",
+		env:   EnvPrefix + "CHECKER_IMAGE",
+		usage: "Image containing the hc-checker binary, used to run built-in probes.",
+		value: func(cfg *Config) flag.Value { return (*stringValue)(&cfg.CheckerImage) },
+	},
+	{
+		name:  "default-cron-pattern",
+		env:   EnvPrefix + "DEFAULT_CRON_PATTERN",
+		usage: "Schedule of HealthChecks that set neither a frequency nor a cronPattern.",
+		value: func(cfg *Config) flag.Value { return (*stringValue)(&cfg.DefaultCronPattern) },
+	},
+	{
+		name:  "successful-jobs-history-limit",
+		env:   EnvPrefix + "SUCCESSFUL_JOBS_HISTORY_LIMIT",
+		usage: "Number of successful Jobs kept for HealthChecks that don't set a limit.",
+		value: func(cfg *Config) flag.Value { return (*int32Value)(&cfg.SuccessfulJobsHistoryLimit) },
+	},
+	{
+		name:  "failed-jobs-history-limit",
+		env:   EnvPrefix + "FAILED_JOBS_HISTORY_LIMIT",
+		usage: "Number of failed Jobs kept for HealthChecks that don't set a limit.",
+		value: func(cfg *Config) flag.Value { return (*int32Value)(&cfg.FailedJobsHistoryLimit) },
+	},
+}
+
+// ApplyEnv overrides the settings whose environment variables are set.
+// lookup is usually os.LookupEnv.
+func (cfg *Config) ApplyEnv(lookup func(key string) (string, bool)) error {
+	for _, s := range settings {
+		if value, ok := lookup(s.env); ok {
+			if err := s.value(cfg).Set(value); err != nil {
+				return fmt.Errorf("invalid value %q for %s: %s", value, s.env, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// Flags holds the settings given on the command line, which override the
+// configuration file and environment variables.
+type Flags struct {
+	fs     *flag.FlagSet
+	values Config
+}
+
+// AddFlags registers a flag on fs for every setting.
+func AddFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs, values: *Default()}
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s Overrides the configuration file and %s.", s.usage, s.env)
+		fs.Var(s.value(&f.values), s.name, usage)
+	}
+	return f
+}
+
+// Apply overrides the settings whose flags were given. It must be called after
+// the flags are parsed.
+func (f *Flags) Apply(cfg *Config) {
+	set := map[string]bool{}
+	f.fs.Visit(func(fl *flag.Flag) {
+		set[fl.Name] = true
+	})
+	for _, s := range settings {
+		if set[s.name] {
+			// The flag was parsed into f.values, so this can't fail.
+			_ = s.value(cfg).Set(s.value(&f.values).String())
+		}
+	}
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+func (v *stringValue) String() string { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type int32Value int32
+
+func (v *int32Value) Set(s string) error {
+	i, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*v = int32Value(i)
+	return nil
+}
+
+func (v *int32Value) String() string { return strconv.FormatInt(int64(*v), 10) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
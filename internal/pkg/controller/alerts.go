@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/alerting"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AlertSent is used as part of the Event 'reason' when a notification is
+	// sent to one of a HealthCheck's receivers.
+	AlertSent = "AlertSent"
+	// ErrAlertFailed is used as part of the Event 'reason' when a
+	// notification can't be sent to one of a HealthCheck's receivers.
+	ErrAlertFailed = "ErrAlertFailed"
+
+	// MessageAlertFiring is the message used for an Event fired when a
+	// receiver is notified that a HealthCheck is unhealthy.
+	MessageAlertFiring = "Notified receiver %q that HealthCheck is unhealthy"
+	// MessageAlertResolved is the message used for an Event fired when a
+	// receiver is notified that a HealthCheck is healthy again.
+	MessageAlertResolved = "Notified receiver %q that HealthCheck is healthy again"
+	// MessageAlertFailed is the message used for Events when a receiver
+	// can't be notified.
+	MessageAlertFailed = "Couldn't notify receiver %q: %s"
+)
+
+// alert is a notification to one of a HealthCheck's receivers.
+type alert struct {
+	receiver healthv1alpha1.AlertReceiver
+	resolved bool
+	// previous is the receiver's state before the notification, which is
+	// restored if it can't be sent.
+	previous healthv1alpha1.AlertStatus
+}
+
+// planAlerts returns the notifications the HealthCheck's receivers should be
+// sent about changes to its health, and records them in its status as if they
+// had been sent, so that notifications are only repeated once the repeat
+// interval has passed.
+func (c *Controller) planAlerts(hc *healthv1alpha1.HealthCheck) []alert {
+	spec := hc.Spec.Alerting
+	if spec == nil {
+		hc.Status.Alerts = nil
+		return nil
+	}
+
+	var repeatInterval time.Duration
+	if spec.RepeatInterval != "" {
+		// The spec has already been validated.
+		if freq, err := frequency.ParseFrequency(spec.RepeatInterval); err == nil {
+			repeatInterval = freq.ToDuration()
+		}
+	}
+
+	now := c.clock.Now()
+	if runsPaused(hc.Spec, now) {
+		// Planned downtime shouldn't page anyone. Anything that changed is
+		// sent once runs resume.
+		return nil
+	}
+
+	if hc.Status.ThresholdState == "" {
+		// A new HealthCheck isn't healthy until it reaches SuccessThreshold,
+		// but that isn't worth paging anyone for either.
+		return nil
+	}
+	firing := !hc.Status.Healthy
+	previous := make(map[string]healthv1alpha1.AlertStatus, len(hc.Status.Alerts))
+	for _, state := range hc.Status.Alerts {
+		previous[state.Receiver] = state
+	}
+
+	var alerts []alert
+	states := make([]healthv1alpha1.AlertStatus, 0, len(spec.Receivers))
+	for _, receiver := range spec.Receivers {
+		state, ok := previous[receiver.Name]
+		if !ok {
+			state = healthv1alpha1.AlertStatus{Receiver: receiver.Name}
+		}
+
+		send := false
+		switch {
+		case firing && !state.Firing:
+			send = true
+		case firing && repeatInterval > 0 && state.LastSentTime != nil:
+			send = now.Sub(state.LastSentTime.Time) >= repeatInterval
+		case !firing && state.Firing:
+			send = receiver.SendResolved
+			if !send {
+				state.Firing = false
+			}
+		}
+
+		if send {
+			alerts = append(alerts, alert{receiver: receiver, resolved: !firing, previous: *state.DeepCopy()})
+			sent := metav1.NewTime(now)
+			state.Firing = firing
+			state.LastSentTime = &sent
+		}
+		states = append(states, state)
+	}
+	hc.Status.Alerts = states
+	return alerts
+}
+
+// sendAlerts sends the notifications planned for the HealthCheck. If any
+// can't be sent, it returns true along with the HealthCheck's alert states
+// with those notifications taken back out.
+func (c *Controller) sendAlerts(hc *healthv1alpha1.HealthCheck, alerts []alert) ([]healthv1alpha1.AlertStatus, bool) {
+	now := c.clock.Now()
+	// Receivers are notified concurrently so a sync waits for at most one
+	// notifier timeout, however many receivers are slow.
+	errs := make([]error, len(alerts))
+	var wg sync.WaitGroup
+	for i, a := range alerts {
+		wg.Add(1)
+		go func(i int, a alert) {
+			defer wg.Done()
+			errs[i] = c.notify(hc, a.receiver, a.resolved, now)
+		}(i, a)
+	}
+	wg.Wait()
+
+	var restored []healthv1alpha1.AlertStatus
+	for i, a := range alerts {
+		if err := errs[i]; err != nil {
+			c.recorder.Event(eventObject(hc), corev1.EventTypeWarning, ErrAlertFailed, fmt.Sprintf(MessageAlertFailed, a.receiver.Name, err.Error()))
+			if restored == nil {
+				restored = make([]healthv1alpha1.AlertStatus, len(hc.Status.Alerts))
+				copy(restored, hc.Status.Alerts)
+			}
+			for i := range restored {
+				if restored[i].Receiver == a.receiver.Name {
+					restored[i] = a.previous
+				}
+			}
+			continue
+		}
+		if a.resolved {
+			c.recorder.Event(eventObject(hc), corev1.EventTypeNormal, AlertSent, fmt.Sprintf(MessageAlertResolved, a.receiver.Name))
+		} else {
+			c.recorder.Event(eventObject(hc), corev1.EventTypeWarning, AlertSent, fmt.Sprintf(MessageAlertFiring, a.receiver.Name))
+		}
+	}
+	return restored, restored != nil
+}
+
+// notify sends a single notification to a receiver.
+func (c *Controller) notify(hc *healthv1alpha1.HealthCheck, spec healthv1alpha1.AlertReceiver, resolved bool, now time.Time) error {
+	receiver := alerting.Receiver{
+		Name:     spec.Name,
+		Type:     spec.Type,
+		URL:      spec.URL,
+		Template: spec.Template,
+	}
+	var err error
+	if spec.URLSecretRef != nil {
+		if receiver.URL, err = c.secretValue(hc.GetNamespace(), spec.URLSecretRef); err != nil {
+			return err
+		}
+	}
+	if spec.RoutingKeySecretRef != nil {
+		if receiver.RoutingKey, err = c.secretValue(hc.GetNamespace(), spec.RoutingKeySecretRef); err != nil {
+			return err
+		}
+	}
+
+	return c.notifier.Notify(receiver, alerting.Alert{
+		Namespace:          objectNamespace(hc),
+		Name:               hc.GetName(),
+		Resolved:           resolved,
+		AverageHealthiness: hc.Status.AverageHealthiness,
+		Last10:             hc.Status.Last10,
+		Time:               now,
+	})
+}
+
+// secretValue reads a key of a Secret.
+func (c *Controller) secretValue(namespace string, ref *corev1.SecretKeySelector) (string, error) {
+	secret, err := c.secretsLister.Secrets(namespace).Get(ref.Name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q", ref.Name, ref.Key)
+	}
+	return string(value), nil
+}
+
+// validateAlerting checks that every receiver can be notified.
+func validateAlerting(spec *healthv1alpha1.Alerting) error {
+	if spec.RepeatInterval != "" {
+		if _, err := frequency.ParseFrequency(spec.RepeatInterval); err != nil {
+			return fmt.Errorf("alerting has an invalid repeatInterval: %s", err.Error())
+		}
+	}
+	names := make(map[string]bool, len(spec.Receivers))
+	for _, receiver := range spec.Receivers {
+		if len(receiver.Name) == 0 {
+			return fmt.Errorf("alert receivers must have a name")
+		}
+		if names[receiver.Name] {
+			return fmt.Errorf("alert receiver %q is defined more than once", receiver.Name)
+		}
+		names[receiver.Name] = true
+
+		hasURL := len(receiver.URL) > 0 || receiver.URLSecretRef != nil
+		if len(receiver.URL) > 0 {
+			if err := alerting.ValidateURL(receiver.URL); err != nil {
+				return fmt.Errorf("alert receiver %q: %s", receiver.Name, err.Error())
+			}
+		}
+		switch receiver.Type {
+		case healthv1alpha1.AlertReceiverWebhook, healthv1alpha1.AlertReceiverSlack:
+			if !hasURL {
+				return fmt.Errorf("alert receiver %q must have a url or urlSecretRef", receiver.Name)
+			}
+		case healthv1alpha1.AlertReceiverPagerDuty:
+			if receiver.RoutingKeySecretRef == nil {
+				return fmt.Errorf("alert receiver %q must have a routingKeySecretRef", receiver.Name)
+			}
+		default:
+			return fmt.Errorf("alert receiver %q has unknown type %q, must be webhook, slack or pagerduty", receiver.Name, receiver.Type)
+		}
+		if err := alerting.ParseTemplate(receiver.Template); err != nil {
+			return fmt.Errorf("alert receiver %q: %s", receiver.Name, err.Error())
+		}
+	}
+	return nil
+}
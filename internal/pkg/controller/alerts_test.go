@@ -0,0 +1,398 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/alerting"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+)
+
+// fakeNotifier records the notifications sent to it instead of sending them.
+type fakeNotifier struct {
+	mu        sync.Mutex
+	sent      []alerting.Alert
+	receivers []alerting.Receiver
+	err       error
+}
+
+func (n *fakeNotifier) Notify(receiver alerting.Receiver, alert alerting.Alert) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, alert)
+	n.receivers = append(n.receivers, receiver)
+	return nil
+}
+
+func newAlertingHealthCheck(sendResolved bool) *healthv1alpha1.HealthCheck {
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", nil)
+	hc.Spec.Alerting = &healthv1alpha1.Alerting{
+		Receivers: []healthv1alpha1.AlertReceiver{
+			{Name: "ops", Type: healthv1alpha1.AlertReceiverWebhook, URL: "http://example.com/hook", SendResolved: sendResolved},
+		},
+		RepeatInterval: "1h",
+	}
+	return hc
+}
+
+func TestSendAlerts(t *testing.T) {
+	lastRunTime := metav1.NewTime(testTime)
+	sentBefore := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(testTime.Add(-d))
+		return &t
+	}
+	sentNow := metav1.NewTime(testTime)
+
+	tests := []struct {
+		name         string
+		healthy      bool
+		sendResolved bool
+		// unknown is true for a new HealthCheck that hasn't reached a
+		// threshold yet.
+		unknown  bool
+		previous []healthv1alpha1.AlertStatus
+		expected []healthv1alpha1.AlertStatus
+		sent     int
+	}{
+		{
+			name:     "becomes unhealthy",
+			expected: []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: &sentNow}},
+			sent:     1,
+		},
+		{
+			name:     "still unhealthy",
+			previous: []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: sentBefore(time.Minute)}},
+			expected: []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: sentBefore(time.Minute)}},
+		},
+		{
+			name:     "repeat interval passed",
+			previous: []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: sentBefore(time.Hour)}},
+			expected: []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: &sentNow}},
+			sent:     1,
+		},
+		{
+			name:         "resolved",
+			healthy:      true,
+			sendResolved: true,
+			previous:     []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: sentBefore(time.Minute)}},
+			expected:     []healthv1alpha1.AlertStatus{{Receiver: "ops", LastSentTime: &sentNow}},
+			sent:         1,
+		},
+		{
+			name:     "resolved without notification",
+			healthy:  true,
+			previous: []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: sentBefore(time.Minute)}},
+			expected: []healthv1alpha1.AlertStatus{{Receiver: "ops", LastSentTime: sentBefore(time.Minute)}},
+		},
+		{
+			name:     "still healthy",
+			healthy:  true,
+			expected: []healthv1alpha1.AlertStatus{{Receiver: "ops"}},
+		},
+		{
+			name:    "no threshold reached",
+			unknown: true,
+		},
+	}
+	for _, test := range tests {
+		tc := newTestCase(t)
+		c, _, _ := tc.newController()
+		hc := newAlertingHealthCheck(test.sendResolved)
+		hc.Status.LastRunTime = &lastRunTime
+		hc.Status.Healthy = test.healthy
+		if !test.unknown {
+			hc.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+			if test.healthy {
+				hc.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+			}
+		}
+		hc.Status.Alerts = test.previous
+
+		c.sendAlerts(hc, c.planAlerts(hc))
+		if !reflect.DeepEqual(hc.Status.Alerts, test.expected) {
+			t.Errorf("%s: expected alerts %+v but got %+v", test.name, test.expected, hc.Status.Alerts)
+		}
+		if len(tc.notifier.sent) != test.sent {
+			t.Errorf("%s: expected %d notifications but got %d", test.name, test.sent, len(tc.notifier.sent))
+		}
+		if test.sent > 0 && tc.notifier.sent[0].Resolved != test.healthy {
+			t.Errorf("%s: expected notification with resolved %t", test.name, test.healthy)
+		}
+	}
+}
+
+func TestSendAlertsRetriesFailures(t *testing.T) {
+	tc := newTestCase(t)
+	tc.notifier = &fakeNotifier{err: fmt.Errorf("connection refused")}
+	c, _, _ := tc.newController()
+	hc := newAlertingHealthCheck(false)
+	lastRunTime := metav1.NewTime(testTime)
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+
+	restored, failed := c.sendAlerts(hc, c.planAlerts(hc))
+	if !failed {
+		t.Fatalf("expected the notification to fail")
+	}
+	expected := []healthv1alpha1.AlertStatus{{Receiver: "ops"}}
+	if !reflect.DeepEqual(restored, expected) {
+		t.Errorf("expected alerts %+v but got %+v", expected, restored)
+	}
+}
+
+func TestSendAlertsReadsSecrets(t *testing.T) {
+	tc := newTestCase(t)
+	tc.secretLister = append(tc.secretLister, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hooks", Namespace: metav1.NamespaceDefault},
+		Data:       map[string][]byte{"ops": []byte("https://hooks.example.com/ops")},
+	})
+	c, _, _ := tc.newController()
+	hc := newAlertingHealthCheck(false)
+	hc.Spec.Alerting.Receivers[0].URL = ""
+	hc.Spec.Alerting.Receivers[0].URLSecretRef = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "hooks"},
+		Key:                  "ops",
+	}
+	lastRunTime := metav1.NewTime(testTime)
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+
+	if _, failed := c.sendAlerts(hc, c.planAlerts(hc)); failed {
+		t.Fatalf("expected the notification to be sent")
+	}
+	if len(tc.notifier.receivers) != 1 || tc.notifier.receivers[0].URL != "https://hooks.example.com/ops" {
+		t.Errorf("expected the URL to be read from the Secret, got %+v", tc.notifier.receivers)
+	}
+}
+
+func TestSendAlertsToSeveralReceivers(t *testing.T) {
+	tc := newTestCase(t)
+	c, _, _ := tc.newController()
+	hc := newAlertingHealthCheck(false)
+	hc.Spec.Alerting.Receivers = append(hc.Spec.Alerting.Receivers,
+		healthv1alpha1.AlertReceiver{Name: "chat", Type: healthv1alpha1.AlertReceiverSlack, URL: "https://hooks.slack.com/x"})
+	lastRunTime := metav1.NewTime(testTime)
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+
+	c.sendAlerts(hc, c.planAlerts(hc))
+	if len(tc.notifier.sent) != 2 {
+		t.Errorf("expected 2 notifications but got %d", len(tc.notifier.sent))
+	}
+}
+
+func TestAlertsOnFailedRun(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newAlertingHealthCheck(false)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", false, testTime))
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{false}
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 1), checkFailed)
+	expected.Status.Alerts = []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: &lastRunTime}}
+	setHistory(&expected.Status, checkResult("foo-1", false, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+
+	if len(tc.notifier.sent) != 1 {
+		t.Errorf("expected 1 notification but got %d", len(tc.notifier.sent))
+	}
+}
+
+func TestNoAlertsBeforeThresholdReached(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   func(spec *healthv1alpha1.HealthCheckSpec)
+		passed bool
+	}{
+		{
+			// A new check isn't healthy until it passes three times, but
+			// it isn't failing either.
+			name:   "passing below success threshold",
+			spec:   func(spec *healthv1alpha1.HealthCheckSpec) { spec.SuccessThreshold = 3 },
+			passed: true,
+		},
+		{
+			name: "failing below failure threshold",
+			spec: func(spec *healthv1alpha1.HealthCheckSpec) { spec.FailureThreshold = 3 },
+		},
+	}
+	for _, test := range tests {
+		tc := newTestCase(t)
+		healthCheckName := "foo"
+		hc := newAlertingHealthCheck(false)
+		test.spec(&hc.Spec)
+		cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+		tc.hcLister = append(tc.hcLister, hc)
+		tc.objects = append(tc.objects, hc)
+		tc.cjLister = append(tc.cjLister, cj)
+		tc.kubeObjects = append(tc.kubeObjects, cj)
+		tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", test.passed, testTime))
+
+		expected := hc.DeepCopy()
+		lastRunTime := metav1.NewTime(testTime)
+		expected.Status.Last10 = []bool{test.passed}
+		expected.Status.LastRunTime = &lastRunTime
+		if test.passed {
+			expected.Status.AverageHealthiness = 1
+			expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 1),
+				newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonSuccessThresholdNotReached, fmt.Sprintf(MessageSuccessThresholdNotReached, 1, 3)))
+		} else {
+			expected.Status.LastFailureReason = ReasonCheckFailed
+			expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 1),
+				newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonFailureThresholdNotReached, fmt.Sprintf(MessageFailureThresholdNotReached, 1, 3)))
+		}
+		setHistory(&expected.Status, checkResult("foo-1", test.passed, testTime))
+		tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+		tc.run(getKey(t, hc))
+
+		if len(tc.notifier.sent) != 0 {
+			t.Errorf("%s: expected no notifications but got %d", test.name, len(tc.notifier.sent))
+		}
+	}
+}
+
+func TestAlertsFailedOnRunAreRetried(t *testing.T) {
+	tc := newTestCase(t)
+	tc.notifier = &fakeNotifier{err: fmt.Errorf("connection refused")}
+	healthCheckName := "foo"
+	hc := newAlertingHealthCheck(false)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", false, testTime))
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{false}
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 1), checkFailed)
+	expected.Status.Alerts = []healthv1alpha1.AlertStatus{{Receiver: "ops", Firing: true, LastSentTime: &lastRunTime}}
+	setHistory(&expected.Status, checkResult("foo-1", false, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	// The notification couldn't be sent, so it's taken back out of the status.
+	reverted := expected.DeepCopy()
+	reverted.Status.Alerts = []healthv1alpha1.AlertStatus{{Receiver: "ops"}}
+	tc.expectUpdateHealthCheckStatusAction(reverted, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestNoAlertsWhenStatusUpdateFails(t *testing.T) {
+	tc := newTestCase(t)
+	hc := newAlertingHealthCheck(false)
+	cj := newCronJob(hc, "foo", "* * * * *", testConfig)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", false, testTime))
+
+	c, _, _ := tc.newController()
+	tc.client.PrependReactor("update", "healthchecks", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewConflict(healthv1alpha1.Resource("healthchecks"), "foo", fmt.Errorf("stale"))
+	})
+	if err := c.syncHandler(getKey(t, hc)); err == nil {
+		t.Errorf("expected the conflict to be returned")
+	}
+	if len(tc.notifier.sent) != 0 {
+		t.Errorf("expected no notifications before the status is written, got %d", len(tc.notifier.sent))
+	}
+}
+
+func TestValidateAlerting(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  healthv1alpha1.Alerting
+		valid bool
+	}{
+		{
+			name: "valid",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: "http://example.com/"},
+				{Name: "pd", Type: healthv1alpha1.AlertReceiverPagerDuty, RoutingKeySecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "pagerduty"},
+					Key:                  "routingKey",
+				}},
+			}},
+			valid: true,
+		},
+		{
+			name: "duplicate name",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: "http://example.com/"},
+				{Name: "hook", Type: healthv1alpha1.AlertReceiverSlack, URL: "http://example.com/"},
+			}},
+		},
+		{
+			name: "missing url",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "slack", Type: healthv1alpha1.AlertReceiverSlack},
+			}},
+		},
+		{
+			name: "unknown type",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "email", Type: "email", URL: "mailto:ops@example.com"},
+			}},
+		},
+		{
+			name: "non-http url",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: "file:///var/run/secrets/token"},
+			}},
+		},
+		{
+			name: "relative url",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: "/hook"},
+			}},
+		},
+		{
+			name: "bad template",
+			spec: healthv1alpha1.Alerting{Receivers: []healthv1alpha1.AlertReceiver{
+				{Name: "hook", Type: healthv1alpha1.AlertReceiverWebhook, URL: "http://example.com/", Template: "{{.Name"},
+			}},
+		},
+		{
+			name: "bad repeat interval",
+			spec: healthv1alpha1.Alerting{RepeatInterval: "soon"},
+		},
+	}
+	for _, test := range tests {
+		err := validateAlerting(&test.spec)
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// clusterHealthCheckKind is the kind of the ClusterHealthCheck resource.
+const clusterHealthCheckKind = "ClusterHealthCheck"
+
+// ClusterHealthChecks are synced by the same code as HealthChecks, through a
+// HealthCheck that stands in for the ClusterHealthCheck in its execution
+// namespace. The stand-in keeps the ClusterHealthCheck's name and UID, so the
+// Jobs and CronJobs it creates are owned by the ClusterHealthCheck, and its
+// kind is set to ClusterHealthCheck so that status updates and events go to
+// the right resource.
+//
+// ClusterHealthChecks share the HealthCheck workqueue. Their keys have no
+// namespace, which no HealthCheck's key can lack.
+
+// getHealthCheck returns the HealthCheck with the given workqueue key parts,
+// or the stand-in for a ClusterHealthCheck if the namespace is empty.
+func (c *Controller) getHealthCheck(namespace, name string) (*healthv1alpha1.HealthCheck, error) {
+	if namespace == "" {
+		if c.clusterhealthchecksLister == nil {
+			return nil, errors.NewNotFound(healthv1alpha1.Resource("clusterhealthchecks"), name)
+		}
+		chc, err := c.clusterhealthchecksLister.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return c.clusterHealthCheckView(chc), nil
+	}
+	return c.healthchecksLister.HealthChecks(namespace).Get(name)
+}
+
+// clusterHealthCheckView returns the HealthCheck that stands in for a
+// ClusterHealthCheck.
+func (c *Controller) clusterHealthCheckView(chc *healthv1alpha1.ClusterHealthCheck) *healthv1alpha1.HealthCheck {
+	hc := &healthv1alpha1.HealthCheck{
+		TypeMeta:   metav1.TypeMeta{APIVersion: healthv1alpha1.SchemeGroupVersion.String(), Kind: clusterHealthCheckKind},
+		ObjectMeta: *chc.ObjectMeta.DeepCopy(),
+		Spec:       *chc.Spec.HealthCheckSpec.DeepCopy(),
+		Status:     *chc.Status.DeepCopy(),
+	}
+	hc.Namespace = chc.Spec.ExecutionNamespace
+	if hc.Namespace == "" {
+		hc.Namespace = c.clusterCheckNamespace
+	}
+	return hc
+}
+
+// handlesClusterHealthCheck returns true if the controller should sync the
+// ClusterHealthCheck. A controller watching a single namespace only syncs the
+// ClusterHealthChecks that run in it.
+func (c *Controller) handlesClusterHealthCheck(obj interface{}) bool {
+	if c.namespace == "" {
+		return true
+	}
+	chc, ok := obj.(*healthv1alpha1.ClusterHealthCheck)
+	if !ok {
+		// Deleted objects are let through, which at most removes metrics
+		// that weren't exported.
+		return true
+	}
+	return c.clusterHealthCheckView(chc).GetNamespace() == c.namespace
+}
+
+// isClusterHealthCheck returns true if hc stands in for a ClusterHealthCheck.
+func isClusterHealthCheck(hc *healthv1alpha1.HealthCheck) bool {
+	return hc.Kind == clusterHealthCheckKind
+}
+
+// clusterHealthCheck returns the ClusterHealthCheck that hc stands in for.
+// Its execution namespace is always set, to the namespace hc is in.
+func clusterHealthCheck(hc *healthv1alpha1.HealthCheck) *healthv1alpha1.ClusterHealthCheck {
+	chc := &healthv1alpha1.ClusterHealthCheck{
+		ObjectMeta: *hc.ObjectMeta.DeepCopy(),
+		Spec: healthv1alpha1.ClusterHealthCheckSpec{
+			HealthCheckSpec:    *hc.Spec.DeepCopy(),
+			ExecutionNamespace: hc.GetNamespace(),
+		},
+		Status: *hc.Status.DeepCopy(),
+	}
+	chc.Namespace = ""
+	return chc
+}
+
+// objectName returns the name that the CronJobs and Jobs running hc are named
+// after and labelled with. The names of ClusterHealthChecks are prefixed, so
+// that they don't collide with a HealthCheck of the same name in their
+// execution namespace.
+func objectName(hc *healthv1alpha1.HealthCheck) string {
+	if isClusterHealthCheck(hc) {
+		return "cluster-" + hc.GetName()
+	}
+	return hc.GetName()
+}
+
+// objectNamespace returns the namespace of the resource behind hc, which is
+// empty for a ClusterHealthCheck.
+func objectNamespace(hc *healthv1alpha1.HealthCheck) string {
+	if isClusterHealthCheck(hc) {
+		return ""
+	}
+	return hc.GetNamespace()
+}
+
+// eventObject returns the object that events about hc are recorded against.
+func eventObject(hc *healthv1alpha1.HealthCheck) runtime.Object {
+	if isClusterHealthCheck(hc) {
+		return clusterHealthCheck(hc)
+	}
+	return hc
+}
+
+// controllerRef returns an owner reference to the resource behind hc.
+func controllerRef(hc *healthv1alpha1.HealthCheck) *metav1.OwnerReference {
+	kind := "HealthCheck"
+	if isClusterHealthCheck(hc) {
+		kind = clusterHealthCheckKind
+	}
+	return metav1.NewControllerRef(hc, healthv1alpha1.SchemeGroupVersion.WithKind(kind))
+}
+
+// updateStatus writes the status of the resource behind hc, and returns the
+// updated HealthCheck.
+func (c *Controller) updateStatus(hc *healthv1alpha1.HealthCheck) (*healthv1alpha1.HealthCheck, error) {
+	if isClusterHealthCheck(hc) {
+		chc, err := c.healthclientset.HealthV1alpha1().ClusterHealthChecks().UpdateStatus(clusterHealthCheck(hc))
+		if err != nil {
+			return nil, err
+		}
+		return c.clusterHealthCheckView(chc), nil
+	}
+	return c.healthclientset.HealthV1alpha1().HealthChecks(hc.GetNamespace()).UpdateStatus(hc)
+}
+
+// patch applies a merge patch to the resource behind hc, and returns the
+// updated HealthCheck.
+func (c *Controller) patch(hc *healthv1alpha1.HealthCheck, patch []byte) (*healthv1alpha1.HealthCheck, error) {
+	if isClusterHealthCheck(hc) {
+		chc, err := c.healthclientset.HealthV1alpha1().ClusterHealthChecks().Patch(hc.GetName(), types.MergePatchType, patch)
+		if err != nil {
+			return nil, err
+		}
+		return c.clusterHealthCheckView(chc), nil
+	}
+	return c.healthclientset.HealthV1alpha1().HealthChecks(hc.GetNamespace()).Patch(hc.GetName(), types.MergePatchType, patch)
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+)
+
+func newClusterHealthCheck(name, executionNamespace string) *healthv1alpha1.ClusterHealthCheck {
+	return &healthv1alpha1.ClusterHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(name + "-uid"),
+		},
+		Spec: healthv1alpha1.ClusterHealthCheckSpec{
+			HealthCheckSpec: healthv1alpha1.HealthCheckSpec{
+				CronPattern: "* * * * *",
+				HTTP:        &healthv1alpha1.HTTPProbe{URL: "https://kubernetes.default.svc/readyz"},
+			},
+			ExecutionNamespace: executionNamespace,
+		},
+	}
+}
+
+func (tc *testCase) expectUpdateClusterHealthCheckStatusAction(chc *healthv1alpha1.ClusterHealthCheck, cronJobName string) {
+	chc.Status.CronJobName = cronJobName
+	action := core.NewRootUpdateAction(schema.GroupVersionResource{Resource: "clusterhealthchecks"}, chc)
+	action.Subresource = "status"
+	tc.actions = append(tc.actions, action)
+}
+
+// expectedClusterCronJob returns the CronJob a ClusterHealthCheck should have
+// in the given namespace.
+func expectedClusterCronJob(t *testing.T, chc *healthv1alpha1.ClusterHealthCheck, namespace string) *batchv1beta1.CronJob {
+	c := &Controller{clusterCheckNamespace: testClusterCheckNamespace}
+	cj := newCronJob(c.clusterHealthCheckView(chc), "cluster-"+chc.Name, "* * * * *", testConfig)
+	if cj.Namespace != namespace {
+		t.Errorf("expected the CronJob to be in namespace %s, got %s", namespace, cj.Namespace)
+	}
+	if ref := metav1.GetControllerOf(cj); ref == nil || ref.Kind != clusterHealthCheckKind || ref.UID != chc.UID {
+		t.Errorf("expected the CronJob to be controlled by the ClusterHealthCheck, got %+v", ref)
+	}
+	return cj
+}
+
+func TestCreatesClusterHealthCheckCronJob(t *testing.T) {
+	tc := newTestCase(t)
+	chc := newClusterHealthCheck("apiserver", "monitoring")
+	tc.chcLister = append(tc.chcLister, chc)
+	tc.objects = append(tc.objects, chc)
+
+	expected := chc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled("cluster-apiserver"), degraded(0, 0), awaitingResults)
+	tc.expectCreateCronJobAction(expectedClusterCronJob(t, chc, "monitoring"))
+	tc.expectUpdateClusterHealthCheckStatusAction(expected, "cluster-apiserver")
+	tc.run("apiserver")
+}
+
+func TestClusterHealthCheckDefaultNamespace(t *testing.T) {
+	tc := newTestCase(t)
+	chc := newClusterHealthCheck("dns", "")
+	tc.chcLister = append(tc.chcLister, chc)
+	tc.objects = append(tc.objects, chc)
+
+	expected := chc.DeepCopy()
+	expected.Spec.ExecutionNamespace = testClusterCheckNamespace
+	expected.Status.Conditions = syncedConditions(cronJobScheduled("cluster-dns"), degraded(0, 0), awaitingResults)
+	tc.expectCreateCronJobAction(expectedClusterCronJob(t, chc, testClusterCheckNamespace))
+	tc.expectUpdateClusterHealthCheckStatusAction(expected, "cluster-dns")
+	tc.run("dns")
+}
+
+func TestRecordsClusterHealthCheckJobResults(t *testing.T) {
+	tc := newTestCase(t)
+	chc := newClusterHealthCheck("apiserver", "monitoring")
+	chc.Status.CronJobName = "cluster-apiserver"
+	cj := expectedClusterCronJob(t, chc, "monitoring")
+	tc.chcLister = append(tc.chcLister, chc)
+	tc.objects = append(tc.objects, chc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	job := newFinishedJob(cj, cronJobKind, "cluster-apiserver-1", true, testTime)
+	job.Labels[controllerLabel] = "cluster-apiserver"
+	tc.jobLister = append(tc.jobLister, job)
+
+	expected := chc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{true}
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	expected.Status.AverageHealthiness = 1
+	expected.Status.Healthy = true
+	setHistory(&expected.Status, healthv1alpha1.CheckResult{JobName: "cluster-apiserver-1", Passed: true, FinishTime: lastRunTime})
+	expected.Status.Conditions = syncedConditions(cronJobScheduled("cluster-apiserver"), degraded(0, 1), checkPassed)
+	tc.expectUpdateClusterHealthCheckStatusAction(expected, "cluster-apiserver")
+	tc.run("apiserver")
+}
+
+func TestClusterHealthCheckBesideSameNamedHealthCheck(t *testing.T) {
+	tc := newTestCase(t)
+	chc := newClusterHealthCheck("apiserver", metav1.NamespaceDefault)
+	tc.chcLister = append(tc.chcLister, chc)
+	tc.objects = append(tc.objects, chc)
+	// A HealthCheck of the same name in the execution namespace, with its own
+	// CronJob and Job.
+	hc := newHealthCheck("apiserver", "nginx", "", "* * * * *", nil)
+	hcCronJob := newCronJob(hc, "apiserver", "* * * * *", testConfig)
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.cjLister = append(tc.cjLister, hcCronJob)
+	tc.kubeObjects = append(tc.kubeObjects, hcCronJob)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(hcCronJob, cronJobKind, "apiserver-1", false, testTime))
+
+	expected := chc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled("cluster-apiserver"), degraded(0, 0), awaitingResults)
+	tc.expectCreateCronJobAction(expectedClusterCronJob(t, chc, metav1.NamespaceDefault))
+	tc.expectUpdateClusterHealthCheckStatusAction(expected, "cluster-apiserver")
+	tc.run("apiserver")
+}
+
+func TestHandleObjectEnqueuesClusterHealthCheck(t *testing.T) {
+	tc := newTestCase(t)
+	chc := newClusterHealthCheck("apiserver", "monitoring")
+	cj := expectedClusterCronJob(t, chc, "monitoring")
+	tc.chcLister = append(tc.chcLister, chc)
+	tc.cjLister = append(tc.cjLister, cj)
+	c, _, _ := tc.newController()
+
+	c.handleObject(newFinishedJob(cj, cronJobKind, "apiserver-1", true, testTime))
+	if c.workqueue.Len() != 1 {
+		t.Fatalf("expected the ClusterHealthCheck to be enqueued, got %d items", c.workqueue.Len())
+	}
+	if key, _ := c.workqueue.Get(); key != "apiserver" {
+		t.Errorf("expected key apiserver, got %v", key)
+	}
+}
+
+func TestHandlesClusterHealthCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		chc       *healthv1alpha1.ClusterHealthCheck
+		handled   bool
+	}{
+		{name: "all namespaces", chc: newClusterHealthCheck("apiserver", "monitoring"), handled: true},
+		{name: "execution namespace", namespace: "monitoring", chc: newClusterHealthCheck("apiserver", "monitoring"), handled: true},
+		{name: "other namespace", namespace: "team-a", chc: newClusterHealthCheck("apiserver", "monitoring")},
+		{name: "default namespace", namespace: testClusterCheckNamespace, chc: newClusterHealthCheck("dns", ""), handled: true},
+	}
+	for _, test := range tests {
+		c := &Controller{clusterCheckNamespace: testClusterCheckNamespace, namespace: test.namespace}
+		if handled := c.handlesClusterHealthCheck(test.chc); handled != test.handled {
+			t.Errorf("%s: expected handled to be %t, got %t", test.name, test.handled, handled)
+		}
+	}
+}
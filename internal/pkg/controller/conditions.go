@@ -0,0 +1,235 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ReasonSpecValid is the condition reason used when a HealthCheck's spec
+	// can be run.
+	ReasonSpecValid = "SpecValid"
+	// ReasonNoConflict is the condition reason used when none of the
+	// resources a HealthCheck needs are managed by something else.
+	ReasonNoConflict = "NoConflict"
+	// ReasonCronJobScheduled is the condition reason used when a HealthCheck
+	// is run by a CronJob.
+	ReasonCronJobScheduled = "CronJobScheduled"
+	// ReasonControllerScheduled is the condition reason used when a
+	// HealthCheck is run by the controller's own scheduler.
+	ReasonControllerScheduled = "ControllerScheduled"
+	// ReasonPodsObserved is the condition reason used when a HealthCheck
+	// samples the state of existing Pods.
+	ReasonPodsObserved = "PodsObserved"
+	// ReasonAwaitingResults is the condition reason used when a HealthCheck
+	// hasn't finished any runs yet.
+	ReasonAwaitingResults = "AwaitingResults"
+	// ReasonCheckPassed is the condition reason used when a HealthCheck is
+	// healthy.
+	ReasonCheckPassed = "CheckPassed"
+	// ReasonCheckFailed is the condition reason used when a HealthCheck is
+	// unhealthy.
+	ReasonCheckFailed = "CheckFailed"
+	// ReasonCheckTimedOut is the condition reason used when a HealthCheck is
+	// unhealthy because its latest run didn't finish in time.
+	ReasonCheckTimedOut = "CheckTimedOut"
+	// ReasonFailureThresholdNotReached is the condition reason used when a
+	// HealthCheck's latest runs failed, but not enough of them to make it
+	// unhealthy.
+	ReasonFailureThresholdNotReached = "FailureThresholdNotReached"
+	// ReasonSuccessThresholdNotReached is the condition reason used when a
+	// HealthCheck's latest runs passed, but not enough of them to make it
+	// healthy.
+	ReasonSuccessThresholdNotReached = "SuccessThresholdNotReached"
+	// ReasonLowAverageHealthiness is the condition reason used when a
+	// HealthCheck is unhealthy because too few of its recent runs passed.
+	ReasonLowAverageHealthiness = "LowAverageHealthiness"
+	// ReasonSuspended is the condition reason used when a HealthCheck is
+	// suspended.
+	ReasonSuspended = "Suspended"
+	// ReasonNotSuspended is the condition reason used when a HealthCheck is
+	// no longer suspended.
+	ReasonNotSuspended = "NotSuspended"
+	// ReasonInMaintenance is the condition reason used when a HealthCheck is
+	// in a maintenance window.
+	ReasonInMaintenance = "InMaintenance"
+	// ReasonNoMaintenance is the condition reason used when a HealthCheck is
+	// not in a maintenance window.
+	ReasonNoMaintenance = "NoMaintenance"
+	// ReasonRecentFailures is the condition reason used when some of a
+	// HealthCheck's recent runs failed.
+	ReasonRecentFailures = "RecentFailures"
+	// ReasonNoRecentFailures is the condition reason used when none of a
+	// HealthCheck's recent runs failed.
+	ReasonNoRecentFailures = "NoRecentFailures"
+
+	// MessageCronJobScheduled is the condition message used when a
+	// HealthCheck is run by a CronJob.
+	MessageCronJobScheduled = "Running on CronJob %q"
+	// MessageControllerScheduled is the condition message used when a
+	// HealthCheck is run by the controller's own scheduler.
+	MessageControllerScheduled = "Running every %s, scheduled by the controller"
+	// MessagePodsObserved is the condition message used when a HealthCheck
+	// samples the state of existing Pods.
+	MessagePodsObserved = "Sampling Pods every %s"
+	// MessageAwaitingResults is the condition message used when a
+	// HealthCheck hasn't finished any runs yet.
+	MessageAwaitingResults = "No check runs have finished yet"
+	// MessageCheckPassed is the condition message used when a HealthCheck is
+	// healthy.
+	MessageCheckPassed = "Latest check run passed"
+	// MessageCheckFailed is the condition message used when a HealthCheck is
+	// unhealthy.
+	MessageCheckFailed = "Latest check run failed"
+	// MessageCheckTimedOut is the condition message used when a HealthCheck
+	// is unhealthy because its latest run didn't finish in time.
+	MessageCheckTimedOut = "Latest check run didn't finish within %ds"
+	// MessageFailureThresholdNotReached is the condition message used when a
+	// HealthCheck's latest runs failed, but not enough of them to make it
+	// unhealthy.
+	MessageFailureThresholdNotReached = "Latest %d check runs failed, %d failures in a row are needed to become unhealthy"
+	// MessageSuccessThresholdNotReached is the condition message used when a
+	// HealthCheck's latest runs passed, but not enough of them to make it
+	// healthy.
+	MessageSuccessThresholdNotReached = "Latest %d check runs passed, %d passes in a row are needed to become healthy"
+	// MessageLowAverageHealthiness is the condition message used when a
+	// HealthCheck is unhealthy because too few of its recent runs passed.
+	MessageLowAverageHealthiness = "Average healthiness %.2f is below the minimum of %.2f"
+	// MessageSuspended is the condition message used when a HealthCheck is
+	// suspended.
+	MessageSuspended = "No new runs are started while the HealthCheck is suspended"
+	// MessageInMaintenance is the condition message used when a HealthCheck
+	// is in a maintenance window.
+	MessageInMaintenance = "In a maintenance window until %s, results are ignored"
+	// MessageRecentFailures is the condition message used when some of a
+	// HealthCheck's recent runs failed.
+	MessageRecentFailures = "%d of the last %d check runs failed"
+	// MessageNoRecentFailures is the condition message used when none of a
+	// HealthCheck's recent runs failed.
+	MessageNoRecentFailures = "None of the last %d check runs failed"
+)
+
+// errorConditions are the conditions recording why a HealthCheck couldn't be
+// synced, and the reasons they are cleared with.
+var errorConditions = []struct {
+	condType    healthv1alpha1.HealthCheckConditionType
+	clearReason string
+}{
+	{healthv1alpha1.HealthCheckInvalidSpec, ReasonSpecValid},
+	{healthv1alpha1.HealthCheckResourceConflict, ReasonNoConflict},
+}
+
+// setCondition adds or updates a condition on the HealthCheck's status. The
+// transition time is only changed if the condition's status changes.
+func (c *Controller) setCondition(hc *healthv1alpha1.HealthCheck, condType healthv1alpha1.HealthCheckConditionType, status corev1.ConditionStatus, reason, message string) {
+	condition := healthv1alpha1.HealthCheckCondition{
+		Type:               condType,
+		Status:             status,
+		ObservedGeneration: hc.GetGeneration(),
+		LastTransitionTime: metav1.NewTime(c.clock.Now()),
+		Reason:             reason,
+		Message:            message,
+	}
+	for i, existing := range hc.Status.Conditions {
+		if existing.Type != condType {
+			continue
+		}
+		if existing.Status == status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		hc.Status.Conditions[i] = condition
+		return
+	}
+	hc.Status.Conditions = append(hc.Status.Conditions, condition)
+}
+
+// setSyncedConditions sets the conditions of a HealthCheck that has been
+// scheduled, based on its recorded results.
+func (c *Controller) setSyncedConditions(hc *healthv1alpha1.HealthCheck, scheduledReason, scheduledMessage string) {
+	for _, ec := range errorConditions {
+		c.setCondition(hc, ec.condType, corev1.ConditionFalse, ec.clearReason, "")
+	}
+	c.setCondition(hc, healthv1alpha1.HealthCheckScheduled, corev1.ConditionTrue, scheduledReason, scheduledMessage)
+
+	status := hc.Status
+	failed := 0
+	for _, passed := range status.Last10 {
+		if !passed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		c.setCondition(hc, healthv1alpha1.HealthCheckDegraded, corev1.ConditionTrue, ReasonRecentFailures, fmt.Sprintf(MessageRecentFailures, failed, len(status.Last10)))
+	} else {
+		c.setCondition(hc, healthv1alpha1.HealthCheckDegraded, corev1.ConditionFalse, ReasonNoRecentFailures, fmt.Sprintf(MessageNoRecentFailures, len(status.Last10)))
+	}
+
+	latest, count := consecutiveResults(status.Last10)
+	switch {
+	case status.LastRunTime == nil:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonAwaitingResults, MessageAwaitingResults)
+	case status.Healthy && latest:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionTrue, ReasonCheckPassed, MessageCheckPassed)
+	case status.Healthy:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionTrue, ReasonFailureThresholdNotReached, fmt.Sprintf(MessageFailureThresholdNotReached, count, failureThreshold(hc.Spec)))
+	case status.ThresholdState == "" && !latest:
+		// A new HealthCheck isn't healthy yet, but isn't failing either.
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonFailureThresholdNotReached, fmt.Sprintf(MessageFailureThresholdNotReached, count, failureThreshold(hc.Spec)))
+	case status.AverageHealthiness < hc.Spec.MinAverageHealthiness:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonLowAverageHealthiness, fmt.Sprintf(MessageLowAverageHealthiness, status.AverageHealthiness, hc.Spec.MinAverageHealthiness))
+	case latest:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonSuccessThresholdNotReached, fmt.Sprintf(MessageSuccessThresholdNotReached, count, successThreshold(hc.Spec)))
+	case status.LastFailureReason == ReasonCheckTimedOut:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonCheckTimedOut, fmt.Sprintf(MessageCheckTimedOut, activeDeadlineSeconds(hc.Spec)))
+	default:
+		c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonCheckFailed, MessageCheckFailed)
+	}
+
+	// The Suspended and InMaintenance conditions are only added once they
+	// first become true.
+	if hc.Spec.Suspend {
+		c.setCondition(hc, healthv1alpha1.HealthCheckSuspended, corev1.ConditionTrue, ReasonSuspended, MessageSuspended)
+	} else if hasCondition(hc, healthv1alpha1.HealthCheckSuspended) {
+		c.setCondition(hc, healthv1alpha1.HealthCheckSuspended, corev1.ConditionFalse, ReasonNotSuspended, "")
+	}
+	if until, ok := maintenanceUntil(hc.Spec, c.clock.Now()); ok {
+		c.setCondition(hc, healthv1alpha1.HealthCheckInMaintenance, corev1.ConditionTrue, ReasonInMaintenance, fmt.Sprintf(MessageInMaintenance, until.UTC().Format(time.RFC3339)))
+	} else if hasCondition(hc, healthv1alpha1.HealthCheckInMaintenance) {
+		c.setCondition(hc, healthv1alpha1.HealthCheckInMaintenance, corev1.ConditionFalse, ReasonNoMaintenance, "")
+	}
+}
+
+// hasCondition returns true if the HealthCheck's status has a condition of
+// the given type.
+func hasCondition(hc *healthv1alpha1.HealthCheck, condType healthv1alpha1.HealthCheckConditionType) bool {
+	for _, condition := range hc.Status.Conditions {
+		if condition.Type == condType {
+			return true
+		}
+	}
+	return false
+}
+
+// updateStatusForSyncError records why a HealthCheck couldn't be synced as a
+// condition of the given type, clearing the other error conditions, and marks
+// the HealthCheck as neither scheduled nor ready.
+func (c *Controller) updateStatusForSyncError(hc *healthv1alpha1.HealthCheck, condType healthv1alpha1.HealthCheckConditionType, reason, message string) error {
+	healthcheckCopy := hc.DeepCopy()
+	healthcheckCopy.Status.ObservedGeneration = hc.GetGeneration()
+	for _, ec := range errorConditions {
+		if ec.condType == condType {
+			c.setCondition(healthcheckCopy, condType, corev1.ConditionTrue, reason, message)
+		} else {
+			c.setCondition(healthcheckCopy, ec.condType, corev1.ConditionFalse, ec.clearReason, "")
+		}
+	}
+	c.setCondition(healthcheckCopy, healthv1alpha1.HealthCheckScheduled, corev1.ConditionFalse, reason, message)
+	c.setCondition(healthcheckCopy, healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, reason, message)
+	_, err := c.updateStatus(healthcheckCopy)
+	return err
+}
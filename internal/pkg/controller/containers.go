@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/checker"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// dnsRecordTypes are the record types DNS probes can look up.
+var dnsRecordTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"SRV":   true,
+	"TXT":   true,
+}
+
+// checkContainer is a container that runs a check, either a user supplied
+// image or a built-in probe.
+type checkContainer struct {
+	name  string
+	image string
+	args  []string
+	probe *checker.Probe
+}
+
+// checkContainers returns the containers a HealthCheck's Pods run checks in.
+// That is a single container named "healthcheck", unless the spec has
+// several Containers.
+func checkContainers(spec healthv1alpha1.HealthCheckSpec) []checkContainer {
+	if len(spec.Containers) == 0 {
+		return []checkContainer{{
+			name:  checkContainerName,
+			image: spec.Image,
+			args:  spec.Args,
+			probe: checker.ProbeFromSpec(spec),
+		}}
+	}
+	checks := make([]checkContainer, 0, len(spec.Containers))
+	for _, c := range spec.Containers {
+		check := checkContainer{name: c.Name, image: c.Image, args: c.Args}
+		if c.HTTP != nil || c.TCP != nil || c.DNS != nil {
+			check.probe = &checker.Probe{HTTP: c.HTTP, TCP: c.TCP, DNS: c.DNS}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// apply sets the fields of a container that the check owns. isNew is true if
+// the container isn't from the HealthCheck's pod template.
+func (check checkContainer) apply(container corev1.Container, isNew bool, checkerImage string) corev1.Container {
+	container.Image = check.image
+	// Checks that fail without writing a termination message still report
+	// the end of their log.
+	if container.TerminationMessagePolicy == "" {
+		container.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+	}
+	if len(check.args) > 0 || isNew {
+		container.Args = check.args
+	}
+	if check.probe != nil {
+		container.Image = checkerImage
+		container.Command = []string{checkerCommand}
+		container.Args = []string{"-probe", check.probe.Encode()}
+		container.Env = mergeEnv(container.Env, check.probe.EnvVars())
+	}
+	return container
+}
+
+// mergeEnv returns env with the variables in set added, replacing any of the
+// same name.
+func mergeEnv(env []corev1.EnvVar, set []corev1.EnvVar) []corev1.EnvVar {
+	if len(set) == 0 {
+		return env
+	}
+	names := make(map[string]bool, len(set))
+	for _, v := range set {
+		names[v.Name] = true
+	}
+	merged := make([]corev1.EnvVar, 0, len(env)+len(set))
+	for _, v := range env {
+		if !names[v.Name] {
+			merged = append(merged, v)
+		}
+	}
+	return append(merged, set...)
+}
+
+// findContainer returns the list of containers or init containers in the Pod
+// spec that has a container with the given name, and its index in that list.
+// The index is -1 if there isn't one.
+func findContainer(spec *corev1.PodSpec, name string) (*[]corev1.Container, int) {
+	for _, list := range []*[]corev1.Container{&spec.Containers, &spec.InitContainers} {
+		for i, c := range *list {
+			if c.Name == name {
+				return list, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+func containerPolicy(spec healthv1alpha1.HealthCheckSpec) healthv1alpha1.ContainerPolicy {
+	if spec.ContainerPolicy == "" {
+		return healthv1alpha1.ParallelContainers
+	}
+	return spec.ContainerPolicy
+}
+
+// validateProbes checks the built-in probes that are set, and returns how
+// many there are.
+func validateProbes(http *healthv1alpha1.HTTPProbe, tcp *healthv1alpha1.TCPProbe, dns *healthv1alpha1.DNSProbe) (int, error) {
+	probes := 0
+	if http != nil {
+		if len(http.URL) == 0 {
+			return 0, fmt.Errorf("http probe must have a url")
+		}
+		if err := checker.ValidateHeadersFrom(http.HeadersFrom); err != nil {
+			return 0, fmt.Errorf("http probe: %s", err.Error())
+		}
+		probes++
+	}
+	if tcp != nil {
+		if len(tcp.Host) == 0 || tcp.Port < 1 || tcp.Port > 65535 {
+			return 0, fmt.Errorf("tcp probe must have a host and a port between 1 and 65535")
+		}
+		probes++
+	}
+	if dns != nil {
+		if len(dns.Name) == 0 {
+			return 0, fmt.Errorf("dns probe must have a name")
+		}
+		// The checker looks up record types in any case.
+		if dns.RecordType != "" && !dnsRecordTypes[strings.ToUpper(dns.RecordType)] {
+			return 0, fmt.Errorf("dns probe has an unsupported recordType %q, expected A, AAAA, CNAME, MX, NS, SRV or TXT", dns.RecordType)
+		}
+		probes++
+	}
+	return probes, nil
+}
+
+// validateContainers checks that each of a HealthCheck's check containers
+// has a unique name and runs exactly one check.
+func validateContainers(spec healthv1alpha1.HealthCheckSpec) error {
+	switch spec.ContainerPolicy {
+	case "", healthv1alpha1.ParallelContainers, healthv1alpha1.SequentialContainers:
+	default:
+		return fmt.Errorf("containerPolicy must be Parallel or Sequential")
+	}
+	names := map[string]bool{}
+	for i, c := range spec.Containers {
+		if errs := validation.IsDNS1123Label(c.Name); len(errs) > 0 {
+			return fmt.Errorf("container %d has an invalid name %q: %s", i, c.Name, errs[0])
+		}
+		if names[c.Name] {
+			return fmt.Errorf("container name %s is used more than once", c.Name)
+		}
+		names[c.Name] = true
+
+		checks, err := validateProbes(c.HTTP, c.TCP, c.DNS)
+		if err != nil {
+			return fmt.Errorf("container %s: %s", c.Name, err.Error())
+		}
+		if len(c.Image) > 0 {
+			checks++
+		} else if len(c.Args) > 0 {
+			return fmt.Errorf("container %s can only set args with an image", c.Name)
+		}
+		if checks != 1 {
+			return fmt.Errorf("container %s must set exactly one of image, http, tcp or dns", c.Name)
+		}
+	}
+	return nil
+}
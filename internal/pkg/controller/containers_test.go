@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/checker"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newContainersHealthCheck(name string, policy healthv1alpha1.ContainerPolicy) *healthv1alpha1.HealthCheck {
+	hc := newHealthCheck(name, "", "", "* * * * *", nil)
+	hc.Spec.ContainerPolicy = policy
+	hc.Spec.Containers = []healthv1alpha1.CheckContainer{
+		{Name: "db", TCP: &healthv1alpha1.TCPProbe{Host: "db", Port: 5432}},
+		{Name: "migrations", Image: "migrations-check", Args: []string{"-v"}},
+		{Name: "api", HTTP: &healthv1alpha1.HTTPProbe{URL: "http://api/healthz"}},
+	}
+	return hc
+}
+
+func probeContainer(name string, probe checker.Probe) corev1.Container {
+	return corev1.Container{
+		Name:                     name,
+		Image:                    testCheckerImage,
+		Command:                  []string{checkerCommand},
+		Args:                     []string{"-probe", probe.Encode()},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
+
+func TestNewJobTemplateContainers(t *testing.T) {
+	hc := newContainersHealthCheck("foo", "")
+	hc.Spec.Template = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "migrations", Command: []string{"/check"}}},
+	}}
+	db := probeContainer("db", checker.Probe{TCP: hc.Spec.Containers[0].TCP})
+	migrations := corev1.Container{
+		Name:                     "migrations",
+		Image:                    "migrations-check",
+		Command:                  []string{"/check"},
+		Args:                     []string{"-v"},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	api := probeContainer("api", checker.Probe{HTTP: hc.Spec.Containers[2].HTTP})
+
+	pod := newJobTemplate(hc, testCheckerImage).Spec.Template.Spec
+	if len(pod.InitContainers) != 0 || !reflect.DeepEqual(pod.Containers, []corev1.Container{db, api, migrations}) {
+		t.Errorf("expected every check to run in parallel, got init containers %+v and containers %+v", pod.InitContainers, pod.Containers)
+	}
+
+	hc.Spec.ContainerPolicy = healthv1alpha1.SequentialContainers
+	pod = newJobTemplate(hc, testCheckerImage).Spec.Template.Spec
+	if !reflect.DeepEqual(pod.InitContainers, []corev1.Container{db, migrations}) || !reflect.DeepEqual(pod.Containers, []corev1.Container{api}) {
+		t.Errorf("expected all but the last check to be init containers, got init containers %+v and containers %+v", pod.InitContainers, pod.Containers)
+	}
+}
+
+func TestNewJobTemplateHeadersFrom(t *testing.T) {
+	hc := newHealthCheck("foo", "", "", "* * * * *", nil)
+	hc.Spec.HTTP = &healthv1alpha1.HTTPProbe{
+		URL: "http://api/healthz",
+		HeadersFrom: []healthv1alpha1.HTTPHeaderSource{{
+			Name: "Authorization",
+			ValueFrom: healthv1alpha1.HTTPHeaderValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "api"},
+				Key:                  "token",
+			}},
+		}},
+	}
+	hc.Spec.Template = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: checkContainerName, Env: []corev1.EnvVar{
+			{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+			{Name: "HC_HEADER_0", Value: "overridden"},
+		}}},
+	}}
+
+	container := newJobTemplate(hc, testCheckerImage).Spec.Template.Spec.Containers[0]
+	expected := []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://proxy:3128"},
+		{Name: "HC_HEADER_0", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: hc.Spec.HTTP.HeadersFrom[0].ValueFrom.SecretKeyRef}},
+	}
+	if !reflect.DeepEqual(container.Env, expected) {
+		t.Errorf("expected env %+v but got %+v", expected, container.Env)
+	}
+}
+
+func TestValidateContainers(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     healthv1alpha1.ContainerPolicy
+		containers []healthv1alpha1.CheckContainer
+		valid      bool
+	}{
+		{name: "parallel", containers: newContainersHealthCheck("foo", "").Spec.Containers, valid: true},
+		{name: "sequential", policy: healthv1alpha1.SequentialContainers, containers: newContainersHealthCheck("foo", "").Spec.Containers, valid: true},
+		{name: "unknown policy", policy: "Sometimes", containers: []healthv1alpha1.CheckContainer{{Name: "a", Image: "nginx"}}},
+		{name: "invalid name", containers: []healthv1alpha1.CheckContainer{{Name: "My_Check", Image: "nginx"}}},
+		{name: "duplicate name", containers: []healthv1alpha1.CheckContainer{{Name: "a", Image: "nginx"}, {Name: "a", Image: "redis"}}},
+		{name: "no check", containers: []healthv1alpha1.CheckContainer{{Name: "a"}}},
+		{name: "image and probe", containers: []healthv1alpha1.CheckContainer{{Name: "a", Image: "nginx", DNS: &healthv1alpha1.DNSProbe{Name: "example.com"}}}},
+		{name: "args for probe", containers: []healthv1alpha1.CheckContainer{{Name: "a", Args: []string{"-v"}, DNS: &healthv1alpha1.DNSProbe{Name: "example.com"}}}},
+		{name: "invalid probe", containers: []healthv1alpha1.CheckContainer{{Name: "a", HTTP: &healthv1alpha1.HTTPProbe{}}}},
+	}
+	for _, test := range tests {
+		spec := healthv1alpha1.HealthCheckSpec{ContainerPolicy: test.policy, Containers: test.containers}
+		if err := ValidateSpec(spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", test.name, test.valid, err)
+		}
+	}
+
+	spec := newContainersHealthCheck("foo", "").Spec
+	spec.Image = "nginx"
+	if err := ValidateSpec(spec); err == nil {
+		t.Errorf("expected an error for a HealthCheck with an image and containers")
+	}
+}
+
+func TestRecordsContainerResults(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newContainersHealthCheck(healthCheckName, healthv1alpha1.SequentialContainers)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	hc.Status.CronJobName = healthCheckName
+
+	// The second check failed, so the third never ran.
+	pod := newCheckPod("foo-1-a", "foo-1", 0, "", testTime)
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "api",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+	}}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{
+		{Name: "db", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			Message: `{"passed":true,"message":"connected to db:5432"}`, FinishedAt: metav1.NewTime(testTime.Add(-time.Second)),
+		}}},
+		{Name: "migrations", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ExitCode: 3, Message: "2 migrations pending", FinishedAt: metav1.NewTime(testTime),
+		}}},
+	}
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", false, testTime))
+	tc.podLister = append(tc.podLister, pod)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	exitCode := int32(3)
+	expected.Status.Last10 = []bool{false}
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	setHistory(&expected.Status, healthv1alpha1.CheckResult{
+		JobName:    "foo-1",
+		Message:    "container migrations failed: 2 migrations pending",
+		ExitCode:   &exitCode,
+		FinishTime: lastRunTime,
+		Containers: []healthv1alpha1.ContainerResult{
+			{Name: "db", Passed: true, Message: "connected to db:5432"},
+			{Name: "migrations", Message: "2 migrations pending", ExitCode: 3},
+		},
+	})
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 1), checkFailed)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
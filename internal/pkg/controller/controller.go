@@ -2,9 +2,11 @@ package controller
 
 import (
 	"fmt"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/api/batch/v1beta1"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -15,10 +17,14 @@ import (
 	clientset "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned"
 	informers "github.com/mbellgb/healthcheck-controller/pkg/generated/informers/externalversions/health/v1alpha1"
 	listers "github.com/mbellgb/healthcheck-controller/pkg/generated/listers/health/v1alpha1"
+	batchv1informers "k8s.io/client-go/informers/batch/v1"
 	batchinformers "k8s.io/client-go/informers/batch/v1beta1"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
 	batchlisters "k8s.io/client-go/listers/batch/v1beta1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -30,6 +36,10 @@ import (
 
 	healthscheme "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned/scheme"
 
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/alerting"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/metrics"
+
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 )
 
@@ -43,35 +53,102 @@ const (
 	// HealthCheck fails to sync due to a Deployment of the same name already
 	// existing.
 	ErrResourceExists = "ErrResourceExists"
+	// ErrInvalidSpec is used as part of the Event 'reason' when a HealthCheck
+	// fails to sync because its spec can't be run.
+	ErrInvalidSpec = "ErrInvalidSpec"
+	// ErrInvalidFrequency is used as part of the Event 'reason' when a
+	// HealthCheck fails to sync because its frequency can't be scheduled.
+	ErrInvalidFrequency = "ErrInvalidFrequency"
 
 	// MessageResourceExists is the message used for Events when a resource
 	// fails to sync due to a Deployment already existing
 	MessageResourceExists = "Resource %q already exists and is not managed by HealthCheck"
+	// MessageInvalidSpec is the message used for Events when a HealthCheck's
+	// spec can't be run.
+	MessageInvalidSpec = "Invalid spec: %s"
+	// MessageInvalidFrequency is the message used for Events when a
+	// HealthCheck's frequency can't be scheduled.
+	MessageInvalidFrequency = "Invalid frequency: %s"
 	// MessageResourceSynced is the message used for an Event fired when a
 	// HealthCheck is synced successfully
 	MessageResourceSynced = "HealthCheck synced successfully"
+
+	// BecameHealthy is used as part of the Event 'reason' when a HealthCheck
+	// transitions from unhealthy to healthy.
+	BecameHealthy = "Healthy"
+	// BecameUnhealthy is used as part of the Event 'reason' when a HealthCheck
+	// transitions from healthy to unhealthy.
+	BecameUnhealthy = "Unhealthy"
+
+	// MessageBecameHealthy is the message used for an Event fired when a
+	// HealthCheck becomes healthy.
+	MessageBecameHealthy = "HealthCheck is healthy, average healthiness %.2f"
+	// MessageBecameUnhealthy is the message used for an Event fired when a
+	// HealthCheck becomes unhealthy.
+	MessageBecameUnhealthy = "HealthCheck is unhealthy, average healthiness %.2f"
 )
 
-// Controller manages HealthCheck resources.
+// Controller manages HealthCheck and ClusterHealthCheck resources.
 type Controller struct {
 	kubeclientset   kubernetes.Interface
 	healthclientset clientset.Interface
 
-	cronjobsLister     batchlisters.CronJobLister
-	cronjobsSynced     cache.InformerSynced
-	healthchecksLister listers.HealthCheckLister
-	healthchecksSynced cache.InformerSynced
+	cronjobsLister            batchlisters.CronJobLister
+	cronjobsSynced            cache.InformerSynced
+	jobsLister                batchv1listers.JobLister
+	jobsSynced                cache.InformerSynced
+	healthchecksLister        listers.HealthCheckLister
+	healthchecksSynced        cache.InformerSynced
+	clusterhealthchecksLister listers.ClusterHealthCheckLister
+	clusterhealthchecksSynced cache.InformerSynced
+	healthcheckgroupsLister   listers.HealthCheckGroupLister
+	healthcheckgroupsSynced   cache.InformerSynced
+	servicesLister            corelisters.ServiceLister
+	servicesSynced            cache.InformerSynced
+	podsLister                corelisters.PodLister
+	podsSynced                cache.InformerSynced
+	secretsLister             corelisters.SecretLister
+	secretsSynced             cache.InformerSynced
 
 	workqueue workqueue.RateLimitingInterface
-	recorder  record.EventRecorder
+	// serviceWorkqueue holds Services that may need HealthChecks generated.
+	serviceWorkqueue workqueue.RateLimitingInterface
+	// groupWorkqueue holds HealthCheckGroups whose status may need updating.
+	groupWorkqueue workqueue.RateLimitingInterface
+	recorder       record.EventRecorder
+	clock          clock.Clock
+
+	// notifier sends the notifications configured by HealthChecks.
+	notifier alerting.Notifier
+
+	// config holds the image used to run built-in probes and the defaults of
+	// HealthChecks.
+	config *config.Config
+	// clusterCheckNamespace is where ClusterHealthChecks that don't set an
+	// execution namespace run.
+	clusterCheckNamespace string
+	// namespace is the only namespace the controller's informers watch, or
+	// empty if they watch every namespace.
+	namespace string
 }
 
-// NewController creates a new healthcheck controller.
+// NewController creates a new healthcheck controller. The informers should
+// all watch the given namespace, or every namespace if it is empty. The
+// ClusterHealthCheck informer may be nil, to leave ClusterHealthChecks alone.
 func NewController(
 	kubeclientset kubernetes.Interface,
 	healthclientset clientset.Interface,
 	cronjobInformer batchinformers.CronJobInformer,
+	jobInformer batchv1informers.JobInformer,
 	healthcheckInformer informers.HealthCheckInformer,
+	clusterhealthcheckInformer informers.ClusterHealthCheckInformer,
+	healthcheckgroupInformer informers.HealthCheckGroupInformer,
+	serviceInformer coreinformers.ServiceInformer,
+	podInformer coreinformers.PodInformer,
+	secretInformer coreinformers.SecretInformer,
+	cfg *config.Config,
+	clusterCheckNamespace string,
+	namespace string,
 ) *Controller {
 	utilruntime.Must(healthscheme.AddToScheme(scheme.Scheme))
 	klog.V(4).Info("Creating event broadcaster")
@@ -80,14 +157,31 @@ func NewController(
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclientset.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
 	controller := &Controller{
-		kubeclientset:      kubeclientset,
-		healthclientset:    healthclientset,
-		cronjobsLister:     cronjobInformer.Lister(),
-		cronjobsSynced:     cronjobInformer.Informer().HasSynced,
-		healthchecksLister: healthcheckInformer.Lister(),
-		healthchecksSynced: healthcheckInformer.Informer().HasSynced,
-		workqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "HealthChecks"),
-		recorder:           recorder,
+		kubeclientset:           kubeclientset,
+		healthclientset:         healthclientset,
+		cronjobsLister:          cronjobInformer.Lister(),
+		cronjobsSynced:          cronjobInformer.Informer().HasSynced,
+		jobsLister:              jobInformer.Lister(),
+		jobsSynced:              jobInformer.Informer().HasSynced,
+		healthchecksLister:      healthcheckInformer.Lister(),
+		healthchecksSynced:      healthcheckInformer.Informer().HasSynced,
+		healthcheckgroupsLister: healthcheckgroupInformer.Lister(),
+		healthcheckgroupsSynced: healthcheckgroupInformer.Informer().HasSynced,
+		servicesLister:          serviceInformer.Lister(),
+		servicesSynced:          serviceInformer.Informer().HasSynced,
+		podsLister:              podInformer.Lister(),
+		podsSynced:              podInformer.Informer().HasSynced,
+		secretsLister:           secretInformer.Lister(),
+		secretsSynced:           secretInformer.Informer().HasSynced,
+		workqueue:               workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), scopedName("HealthChecks", namespace)),
+		serviceWorkqueue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), scopedName("Services", namespace)),
+		groupWorkqueue:          workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), scopedName("HealthCheckGroups", namespace)),
+		recorder:                recorder,
+		clock:                   clock.RealClock{},
+		notifier:                alerting.NewHTTPNotifier(),
+		config:                  cfg,
+		clusterCheckNamespace:   clusterCheckNamespace,
+		namespace:               namespace,
 	}
 
 	klog.Info("Setting up event handlers")
@@ -96,12 +190,82 @@ func NewController(
 		UpdateFunc: func(old, new interface{}) {
 			oldHC := old.(*healthv1alpha1.HealthCheck)
 			newHC := new.(*healthv1alpha1.HealthCheck)
-			if oldHC.ResourceVersion == newHC.ResourceVersion {
+			// Status updates don't change the generation, so only spec
+			// changes, run-now requests and periodic resyncs are enqueued.
+			if oldHC.ResourceVersion == newHC.ResourceVersion || oldHC.Generation != newHC.Generation || runNowRequested(newHC) {
 				controller.enqueueHealthCheck(new)
 			}
 		},
 		DeleteFunc: controller.deleteCronJob,
 	})
+	healthcheckInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: controller.deleteHealthCheckMetrics,
+	})
+	// Generated HealthChecks are put back if they are changed or deleted.
+	// Status updates and resyncs can't change what the Service wants, so
+	// they don't requeue it.
+	healthcheckInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) {
+			if serviceHealthCheckChanged(old.(*healthv1alpha1.HealthCheck), new.(*healthv1alpha1.HealthCheck)) {
+				controller.handleServiceHealthCheck(new)
+			}
+		},
+		DeleteFunc: controller.handleServiceHealthCheck,
+	})
+	// Groups are updated whenever one of their members changes, including
+	// status updates.
+	healthcheckInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleGroupMember,
+		UpdateFunc: func(old, new interface{}) {
+			oldHC := old.(*healthv1alpha1.HealthCheck)
+			newHC := new.(*healthv1alpha1.HealthCheck)
+			if oldHC.ResourceVersion == newHC.ResourceVersion {
+				return
+			}
+			// Groups that no longer select the HealthCheck need updating too.
+			controller.handleGroupMember(old)
+			controller.handleGroupMember(new)
+		},
+		DeleteFunc: controller.handleGroupMember,
+	})
+	if clusterhealthcheckInformer != nil {
+		controller.clusterhealthchecksLister = clusterhealthcheckInformer.Lister()
+		controller.clusterhealthchecksSynced = clusterhealthcheckInformer.Informer().HasSynced
+		// ClusterHealthChecks share the HealthCheck workqueue, and are
+		// enqueued on the same changes.
+		clusterhealthcheckInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: controller.handlesClusterHealthCheck,
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: controller.enqueueHealthCheck,
+				UpdateFunc: func(old, new interface{}) {
+					oldCHC := old.(*healthv1alpha1.ClusterHealthCheck)
+					newCHC := new.(*healthv1alpha1.ClusterHealthCheck)
+					if oldCHC.ResourceVersion == newCHC.ResourceVersion || oldCHC.Generation != newCHC.Generation || runNowRequested(newCHC) {
+						controller.enqueueHealthCheck(new)
+					}
+				},
+				DeleteFunc: controller.deleteHealthCheckMetrics,
+			},
+		})
+	}
+	healthcheckgroupInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueHealthCheckGroup,
+		UpdateFunc: func(old, new interface{}) {
+			controller.enqueueHealthCheckGroup(new)
+		},
+		DeleteFunc: controller.enqueueHealthCheckGroup,
+	})
+	serviceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.enqueueService,
+		UpdateFunc: func(old, new interface{}) {
+			oldSvc := old.(*corev1.Service)
+			newSvc := new.(*corev1.Service)
+			if oldSvc.ResourceVersion != newSvc.ResourceVersion {
+				controller.enqueueService(new)
+			}
+		},
+		DeleteFunc: controller.enqueueService,
+	})
 	cronjobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: controller.handleObject,
 		UpdateFunc: func(old, new interface{}) {
@@ -113,19 +277,33 @@ func NewController(
 		},
 		DeleteFunc: controller.handleObject,
 	})
+	jobInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: controller.handleObject,
+		UpdateFunc: func(old, new interface{}) {
+			oldJob := old.(*batchv1.Job)
+			newJob := new.(*batchv1.Job)
+			if oldJob.ResourceVersion != newJob.ResourceVersion {
+				controller.handleObject(new)
+			}
+		},
+		DeleteFunc: controller.handleObject,
+	})
 
 	return controller
 }
 
 func (c *Controller) deleteCronJob(obj interface{}) {
-	var (
-		hc healthv1alpha1.HealthCheck
-		ok bool
-	)
-	if hc, ok = obj.(healthv1alpha1.HealthCheck); !ok {
-		utilruntime.HandleError(fmt.Errorf("could not decode object into HealthCheck"))
-		fmt.Println(obj)
-		return
+	hc, ok := obj.(*healthv1alpha1.HealthCheck)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object, invalid type"))
+			return
+		}
+		if hc, ok = tombstone.Obj.(*healthv1alpha1.HealthCheck); !ok {
+			utilruntime.HandleError(fmt.Errorf("error decoding object tombstone, invalid type"))
+			return
+		}
 	}
 
 	// Find matching CronJob if any.
@@ -136,6 +314,10 @@ func (c *Controller) deleteCronJob(obj interface{}) {
 		// "Oh, didn't I?"
 		return
 	}
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
 
 	if err := c.kubeclientset.BatchV1beta1().CronJobs(cronjob.GetNamespace()).Delete(cronjob.GetName(), &metav1.DeleteOptions{}); err != nil {
 		utilruntime.HandleError(err)
@@ -149,18 +331,46 @@ func (c *Controller) deleteCronJob(obj interface{}) {
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
+	defer c.serviceWorkqueue.ShutDown()
+	defer c.groupWorkqueue.ShutDown()
 
-	klog.Info("Starting HealthCheck controller")
+	if c.namespace == "" {
+		klog.Info("Starting HealthCheck controller")
+	} else {
+		klog.Infof("Starting HealthCheck controller for namespace %s", c.namespace)
+	}
 
 	klog.Info("Waiting for caches to sync")
-	if ok := cache.WaitForCacheSync(stopCh, c.cronjobsSynced, c.healthchecksSynced); !ok {
+	informersSynced := map[string]cache.InformerSynced{
+		"cronjobs":          c.cronjobsSynced,
+		"jobs":              c.jobsSynced,
+		"healthchecks":      c.healthchecksSynced,
+		"healthcheckgroups": c.healthcheckgroupsSynced,
+		"services":          c.servicesSynced,
+		"pods":              c.podsSynced,
+		"secrets":           c.secretsSynced,
+	}
+	if c.clusterhealthchecksSynced != nil {
+		informersSynced["clusterhealthchecks"] = c.clusterhealthchecksSynced
+	}
+	var cacheSyncs []cache.InformerSynced
+	for name, synced := range informersSynced {
+		metrics.SetInformerSynced(scopedName(name, c.namespace), false)
+		cacheSyncs = append(cacheSyncs, synced)
+	}
+	if ok := cache.WaitForCacheSync(stopCh, cacheSyncs...); !ok {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
+	for name := range informersSynced {
+		metrics.SetInformerSynced(scopedName(name, c.namespace), true)
+	}
 
 	klog.Info("Starting workers")
 	for i := 0; i < threadiness; i++ {
 		// run worker
 		go wait.Until(c.runWorker, time.Second, stopCh)
+		go wait.Until(c.runServiceWorker, time.Second, stopCh)
+		go wait.Until(c.runGroupWorker, time.Second, stopCh)
 	}
 
 	klog.Info("Started workers")
@@ -171,12 +381,22 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 }
 
 func (c *Controller) runWorker() {
-	for c.processNextWorkItem() {
+	for c.processNextWorkItem(c.workqueue, scopedName("HealthChecks", c.namespace), c.syncHandler) {
+	}
+}
+
+func (c *Controller) runServiceWorker() {
+	for c.processNextWorkItem(c.serviceWorkqueue, scopedName("Services", c.namespace), c.syncService) {
 	}
 }
 
-func (c *Controller) processNextWorkItem() bool {
-	obj, shutdown := c.workqueue.Get()
+func (c *Controller) runGroupWorker() {
+	for c.processNextWorkItem(c.groupWorkqueue, scopedName("HealthCheckGroups", c.namespace), c.syncHealthCheckGroup) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(queue workqueue.RateLimitingInterface, queueName string, sync func(string) error) bool {
+	obj, shutdown := queue.Get()
 
 	if shutdown {
 		return false
@@ -184,23 +404,24 @@ func (c *Controller) processNextWorkItem() bool {
 
 	// Work closure
 	err := func(obj interface{}) error {
-		defer c.workqueue.Done(obj)
+		defer queue.Done(obj)
 		var (
 			key string
 			ok  bool
 		)
 
 		if key, ok = obj.(string); !ok {
-			c.workqueue.Forget(obj)
+			queue.Forget(obj)
 			utilruntime.HandleError(fmt.Errorf("expected string in workqueue but got %#v", obj))
 		}
 
-		if err := c.syncHandler(key); err != nil {
-			c.workqueue.AddRateLimited(key)
+		if err := sync(key); err != nil {
+			metrics.RecordSyncError(queueName, key)
+			queue.AddRateLimited(key)
 			return fmt.Errorf("error syncing '%s': %s, requeuing", key, err.Error())
 		}
 
-		c.workqueue.Forget(obj)
+		queue.Forget(obj)
 		klog.Infof("Successfully synced '%s'", key)
 		return nil
 	}(obj)
@@ -224,3 +445,29 @@ func (c *Controller) enqueueHealthCheck(obj interface{}) {
 	}
 	c.workqueue.Add(key)
 }
+
+// deleteHealthCheckMetrics stops exporting the metrics of a deleted
+// HealthCheck.
+func (c *Controller) deleteHealthCheckMetrics(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+	metrics.DeleteHealthCheck(object.GetNamespace(), object.GetName())
+}
+
+// scopedName qualifies the name of a workqueue or informer with the namespace
+// the controller watches, so that controllers watching different namespaces
+// in the same process export separate metrics.
+func scopedName(name, namespace string) string {
+	if namespace == "" {
+		return name
+	}
+	return name + "/" + namespace
+}
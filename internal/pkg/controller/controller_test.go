@@ -1,13 +1,19 @@
 package controller
 
 import (
+	"fmt"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
 	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
 	"github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned/fake"
 	informers "github.com/mbellgb/healthcheck-controller/pkg/generated/informers/externalversions"
+	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
 	kubeinformers "k8s.io/client-go/informers"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
@@ -20,22 +26,44 @@ import (
 )
 
 type testCase struct {
-	t           *testing.T
-	client      *fake.Clientset
-	kubeclient  *k8sfake.Clientset
-	hcLister    []*healthv1alpha1.HealthCheck
-	cjLister    []*batchv1beta1.CronJob
-	kubeActions []core.Action
-	actions     []core.Action
-	kubeObjects []runtime.Object
-	objects     []runtime.Object
+	t            *testing.T
+	client       *fake.Clientset
+	kubeclient   *k8sfake.Clientset
+	hcLister     []*healthv1alpha1.HealthCheck
+	chcLister    []*healthv1alpha1.ClusterHealthCheck
+	groupLister  []*healthv1alpha1.HealthCheckGroup
+	cjLister     []*batchv1beta1.CronJob
+	jobLister    []*batchv1.Job
+	svcLister    []*corev1.Service
+	podLister    []*corev1.Pod
+	secretLister []*corev1.Secret
+	notifier     *fakeNotifier
+	kubeActions  []core.Action
+	actions      []core.Action
+	kubeObjects  []runtime.Object
+	objects      []runtime.Object
 }
 
 var (
 	alwaysReady        = func() bool { return true }
 	noResyncPeriodFunc = func() time.Duration { return 0 }
+	cronJobKind        = batchv1beta1.SchemeGroupVersion.WithKind("CronJob")
+	healthCheckKind    = healthv1alpha1.SchemeGroupVersion.WithKind("HealthCheck")
+	testTime           = time.Date(2020, 1, 1, 0, 0, 5, 0, time.UTC)
+	testCheckerImage   = "hc-checker:test"
+	testConfig         = newTestConfig()
+
+	testClusterCheckNamespace = "hc-system"
 )
 
+// newTestConfig returns the default configuration, with a checker image that is
+// easy to spot.
+func newTestConfig() *config.Config {
+	cfg := config.Default()
+	cfg.CheckerImage = testCheckerImage
+	return cfg
+}
+
 func newTestCase(t *testing.T) *testCase {
 	return &testCase{
 		t:           t,
@@ -50,7 +78,7 @@ func newHealthCheck(name, image, frequency, cronPattern string, args []string) *
 			Name:      name,
 			Namespace: metav1.NamespaceDefault,
 		},
-		Spec:       healthv1alpha1.HealthCheckSpec{
+		Spec: healthv1alpha1.HealthCheckSpec{
 			Image:       image,
 			Frequency:   frequency,
 			CronPattern: cronPattern,
@@ -59,6 +87,32 @@ func newHealthCheck(name, image, frequency, cronPattern string, args []string) *
 	}
 }
 
+func newFinishedJob(owner metav1.Object, gvk schema.GroupVersionKind, name string, passed bool, finished time.Time) *batchv1.Job {
+	condType := batchv1.JobComplete
+	if !passed {
+		condType = batchv1.JobFailed
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: owner.GetNamespace(),
+			Labels:    map[string]string{controllerLabel: "foo"},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(owner, gvk),
+			},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{
+					Type:               condType,
+					Status:             corev1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(finished),
+				},
+			},
+		},
+	}
+}
+
 func (tc *testCase) newController() (*Controller, informers.SharedInformerFactory, kubeinformers.SharedInformerFactory) {
 	tc.client = fake.NewSimpleClientset(tc.objects...)
 	tc.kubeclient = k8sfake.NewSimpleClientset(tc.kubeObjects...)
@@ -69,18 +123,56 @@ func (tc *testCase) newController() (*Controller, informers.SharedInformerFactor
 		tc.kubeclient,
 		tc.client,
 		k8sI.Batch().V1beta1().CronJobs(),
+		k8sI.Batch().V1().Jobs(),
 		i.Health().V1alpha1().HealthChecks(),
+		i.Health().V1alpha1().ClusterHealthChecks(),
+		i.Health().V1alpha1().HealthCheckGroups(),
+		k8sI.Core().V1().Services(),
+		k8sI.Core().V1().Pods(),
+		k8sI.Core().V1().Secrets(),
+		testConfig,
+		testClusterCheckNamespace,
+		metav1.NamespaceAll,
 	)
 	c.cronjobsSynced = alwaysReady
+	c.jobsSynced = alwaysReady
+	c.servicesSynced = alwaysReady
+	c.podsSynced = alwaysReady
+	c.secretsSynced = alwaysReady
 	c.healthchecksSynced = alwaysReady
+	c.clusterhealthchecksSynced = alwaysReady
+	c.healthcheckgroupsSynced = alwaysReady
 	c.recorder = &record.FakeRecorder{}
+	c.clock = clock.NewFakeClock(testTime)
+	if tc.notifier == nil {
+		tc.notifier = &fakeNotifier{}
+	}
+	c.notifier = tc.notifier
 
 	for _, hc := range tc.hcLister {
 		i.Health().V1alpha1().HealthChecks().Informer().GetIndexer().Add(hc)
 	}
+	for _, chc := range tc.chcLister {
+		i.Health().V1alpha1().ClusterHealthChecks().Informer().GetIndexer().Add(chc)
+	}
+	for _, group := range tc.groupLister {
+		i.Health().V1alpha1().HealthCheckGroups().Informer().GetIndexer().Add(group)
+	}
 	for _, cj := range tc.cjLister {
 		k8sI.Batch().V1beta1().CronJobs().Informer().GetIndexer().Add(cj)
 	}
+	for _, job := range tc.jobLister {
+		k8sI.Batch().V1().Jobs().Informer().GetIndexer().Add(job)
+	}
+	for _, svc := range tc.svcLister {
+		k8sI.Core().V1().Services().Informer().GetIndexer().Add(svc)
+	}
+	for _, pod := range tc.podLister {
+		k8sI.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+	}
+	for _, secret := range tc.secretLister {
+		k8sI.Core().V1().Secrets().Informer().GetIndexer().Add(secret)
+	}
 
 	return c, i, k8sI
 }
@@ -93,7 +185,19 @@ func (tc *testCase) runExpectError(hcName string) {
 	tc.runController(hcName, true, true)
 }
 
+func (tc *testCase) runService(svcName string) {
+	tc.runSync(svcName, true, false, (*Controller).syncService)
+}
+
+func (tc *testCase) runGroup(groupName string) {
+	tc.runSync(groupName, true, false, (*Controller).syncHealthCheckGroup)
+}
+
 func (tc *testCase) runController(hcName string, startInformers, expectError bool) {
+	tc.runSync(hcName, startInformers, expectError, (*Controller).syncHandler)
+}
+
+func (tc *testCase) runSync(key string, startInformers, expectError bool, sync func(*Controller, string) error) {
 	c, i, k8sI := tc.newController()
 	if startInformers {
 		stopCh := make(chan struct{})
@@ -102,11 +206,11 @@ func (tc *testCase) runController(hcName string, startInformers, expectError boo
 		k8sI.Start(stopCh)
 	}
 
-	err := c.syncHandler(hcName)
+	err := sync(c, key)
 	if !expectError && err != nil {
-		tc.t.Errorf("error syncing HealthCheck: %v", err)
+		tc.t.Errorf("error syncing %s: %v", key, err)
 	} else if expectError && err == nil {
-		tc.t.Errorf("expected error syncing HealthCheck, got nil")
+		tc.t.Errorf("expected error syncing %s, got nil", key)
 	}
 
 	actions := filterInformerActions(tc.client.Actions())
@@ -162,16 +266,22 @@ func checkAction(t *testing.T, expected, actual core.Action) {
 		expObject := e.GetObject()
 		object := a.GetObject()
 
-		if !reflect.DeepEqual(expObject,object) {
+		if !reflect.DeepEqual(expObject, object) {
 			t.Errorf("Action %s %s has wrong object\nDiff:\n %s",
 				a.GetVerb(), a.GetResource().Resource, diff.ObjectGoPrintSideBySide(expObject, object))
 		}
+	case core.DeleteActionImpl:
+		e, _ := expected.(core.DeleteActionImpl)
+		if e.GetName() != a.GetName() {
+			t.Errorf("Action %s %s has wrong name, expected %s but got %s",
+				a.GetVerb(), a.GetResource().Resource, e.GetName(), a.GetName())
+		}
 	case core.PatchActionImpl:
 		e, _ := expected.(core.PatchActionImpl)
 		expObject := e.GetPatch()
 		object := a.GetPatch()
 
-		if !reflect.DeepEqual(expObject,object) {
+		if !reflect.DeepEqual(expObject, object) {
 			t.Errorf("Action %s %s has wrong patch\nDiff:\n %s",
 				a.GetVerb(), a.GetResource().Resource, diff.ObjectGoPrintSideBySide(expObject, object))
 		}
@@ -187,8 +297,18 @@ func filterInformerActions(actions []core.Action) []core.Action {
 		if len(action.GetNamespace()) == 0 &&
 			(action.Matches("list", "healthchecks") ||
 				action.Matches("watch", "healthchecks") ||
+				action.Matches("list", "clusterhealthchecks") ||
+				action.Matches("watch", "clusterhealthchecks") ||
+				action.Matches("list", "healthcheckgroups") ||
+				action.Matches("watch", "healthcheckgroups") ||
 				action.Matches("list", "cronjobs") ||
-				action.Matches("watch", "cronjobs")) {
+				action.Matches("watch", "cronjobs") ||
+				action.Matches("list", "jobs") ||
+				action.Matches("watch", "jobs") ||
+				action.Matches("list", "services") ||
+				action.Matches("watch", "services") ||
+				action.Matches("list", "pods") ||
+				action.Matches("watch", "pods")) {
 			continue
 		}
 		ret = append(ret, action)
@@ -204,13 +324,82 @@ func (tc *testCase) expectUpdateCronJobAction(cj *batchv1beta1.CronJob) {
 	tc.kubeActions = append(tc.kubeActions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "cronjobs"}, cj.Namespace, cj))
 }
 
+func (tc *testCase) expectDeleteCronJobAction(cj *batchv1beta1.CronJob) {
+	tc.kubeActions = append(tc.kubeActions, core.NewDeleteAction(schema.GroupVersionResource{Resource: "cronjobs"}, cj.Namespace, cj.Name))
+}
+
+func (tc *testCase) expectCreateJobAction(job *batchv1.Job) {
+	tc.kubeActions = append(tc.kubeActions, core.NewCreateAction(schema.GroupVersionResource{Resource: "jobs"}, job.Namespace, job))
+}
+
+func (tc *testCase) expectDeleteJobAction(job *batchv1.Job) {
+	tc.kubeActions = append(tc.kubeActions, core.NewDeleteAction(schema.GroupVersionResource{Resource: "jobs"}, job.Namespace, job.Name))
+}
+
+func (tc *testCase) expectCreateHealthCheckAction(hc *healthv1alpha1.HealthCheck) {
+	tc.actions = append(tc.actions, core.NewCreateAction(schema.GroupVersionResource{Resource: "healthchecks"}, hc.Namespace, hc))
+}
+
+func (tc *testCase) expectUpdateHealthCheckAction(hc *healthv1alpha1.HealthCheck) {
+	tc.actions = append(tc.actions, core.NewUpdateAction(schema.GroupVersionResource{Resource: "healthchecks"}, hc.Namespace, hc))
+}
+
+func (tc *testCase) expectDeleteHealthCheckAction(hc *healthv1alpha1.HealthCheck) {
+	tc.actions = append(tc.actions, core.NewDeleteAction(schema.GroupVersionResource{Resource: "healthchecks"}, hc.Namespace, hc.Name))
+}
+
 func (tc *testCase) expectUpdateHealthCheckStatusAction(hc *healthv1alpha1.HealthCheck, cronJobName string) {
 	hc.Status.CronJobName = cronJobName
 	action := core.NewUpdateAction(schema.GroupVersionResource{Resource: "healthchecks"}, hc.Namespace, hc)
-	//action.Subresource = "status"
+	action.Subresource = "status"
 	tc.actions = append(tc.actions, action)
 }
 
+func newCondition(condType healthv1alpha1.HealthCheckConditionType, status corev1.ConditionStatus, reason, message string) healthv1alpha1.HealthCheckCondition {
+	return healthv1alpha1.HealthCheckCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(testTime),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+func syncedConditions(scheduled, degraded, ready healthv1alpha1.HealthCheckCondition) []healthv1alpha1.HealthCheckCondition {
+	return []healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInvalidSpec, corev1.ConditionFalse, ReasonSpecValid, ""),
+		newCondition(healthv1alpha1.HealthCheckResourceConflict, corev1.ConditionFalse, ReasonNoConflict, ""),
+		scheduled,
+		degraded,
+		ready,
+	}
+}
+
+func cronJobScheduled(name string) healthv1alpha1.HealthCheckCondition {
+	return newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionTrue, ReasonCronJobScheduled, fmt.Sprintf(MessageCronJobScheduled, name))
+}
+
+func controllerScheduled(freq string) healthv1alpha1.HealthCheckCondition {
+	return newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionTrue, ReasonControllerScheduled, fmt.Sprintf(MessageControllerScheduled, freq))
+}
+
+func podsObserved(freq string) healthv1alpha1.HealthCheckCondition {
+	return newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionTrue, ReasonPodsObserved, fmt.Sprintf(MessagePodsObserved, freq))
+}
+
+func degraded(failed, total int) healthv1alpha1.HealthCheckCondition {
+	if failed == 0 {
+		return newCondition(healthv1alpha1.HealthCheckDegraded, corev1.ConditionFalse, ReasonNoRecentFailures, fmt.Sprintf(MessageNoRecentFailures, total))
+	}
+	return newCondition(healthv1alpha1.HealthCheckDegraded, corev1.ConditionTrue, ReasonRecentFailures, fmt.Sprintf(MessageRecentFailures, failed, total))
+}
+
+var (
+	awaitingResults = newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonAwaitingResults, MessageAwaitingResults)
+	checkPassed     = newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionTrue, ReasonCheckPassed, MessageCheckPassed)
+	checkFailed     = newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonCheckFailed, MessageCheckFailed)
+)
+
 func getKey(t *testing.T, hc *healthv1alpha1.HealthCheck) string {
 	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(hc)
 	if err != nil {
@@ -228,42 +417,48 @@ func TestCreatesCronJob(t *testing.T) {
 	tc.hcLister = append(tc.hcLister, hc)
 	tc.objects = append(tc.objects, hc)
 
-	expectedCronJob := newCronJob(hc, healthCheckName)
+	expectedCronJob := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
 	tc.expectCreateCronJobAction(expectedCronJob)
-	tc.expectUpdateHealthCheckStatusAction(hc, healthCheckName)
-	tc.run(getKey(t,hc))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
 }
 
 func TestDoNothing(t *testing.T) {
 	tc := newTestCase(t)
 	healthCheckName := "foo"
 	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
-	cj := newCronJob(hc, healthCheckName)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
 
 	tc.hcLister = append(tc.hcLister, hc)
 	tc.objects = append(tc.objects, hc)
 	tc.cjLister = append(tc.cjLister, cj)
 	tc.kubeObjects = append(tc.kubeObjects, cj)
 
-	tc.expectUpdateHealthCheckStatusAction(hc, healthCheckName)
-	tc.run(getKey(t,hc))
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
 }
 
 func TestUpdateCronJob(t *testing.T) {
 	tc := newTestCase(t)
 	healthCheckName := "foo"
 	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
-	cj := newCronJob(hc, healthCheckName)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
 
 	// Update HealthCheck image.
 	hc.Spec.Image = "busybox"
-	expectedCronJob := newCronJob(hc, healthCheckName)
+	expectedCronJob := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
 	tc.hcLister = append(tc.hcLister, hc)
 	tc.objects = append(tc.objects, hc)
 	tc.cjLister = append(tc.cjLister, cj)
 	tc.kubeObjects = append(tc.kubeObjects, cj)
 
-	tc.expectUpdateHealthCheckStatusAction(hc, healthCheckName)
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
 	tc.expectUpdateCronJobAction(expectedCronJob)
 	tc.run(getKey(t, hc))
 }
@@ -272,15 +467,555 @@ func TestNotControlledByHCController(t *testing.T) {
 	tc := newTestCase(t)
 	healthCheckName := "foo"
 	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
-	cj := newCronJob(hc, healthCheckName)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
 
 	// CronJob not owned by this controller.
 	cj.ObjectMeta.OwnerReferences = []metav1.OwnerReference{}
+	// The spec used to be invalid, which is no longer the problem.
+	hc.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInvalidSpec, corev1.ConditionTrue, ErrInvalidSpec, "invalid"),
+	}
 
 	tc.hcLister = append(tc.hcLister, hc)
 	tc.objects = append(tc.objects, hc)
 	tc.cjLister = append(tc.cjLister, cj)
 	tc.kubeObjects = append(tc.kubeObjects, cj)
 
+	msg := fmt.Sprintf(MessageResourceExists, cj.Name)
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInvalidSpec, corev1.ConditionFalse, ReasonSpecValid, ""),
+		newCondition(healthv1alpha1.HealthCheckResourceConflict, corev1.ConditionTrue, ErrResourceExists, msg),
+		newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionFalse, ErrResourceExists, msg),
+		newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ErrResourceExists, msg),
+	}
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
 	tc.runExpectError(getKey(t, hc))
 }
+
+func TestRecordsJobResults(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister,
+		newFinishedJob(cj, cronJobKind, "foo-1", true, start),
+		newFinishedJob(cj, cronJobKind, "foo-3", false, start.Add(2*time.Minute)),
+		newFinishedJob(cj, cronJobKind, "foo-2", true, start.Add(time.Minute)),
+	)
+
+	// Job that hasn't finished yet.
+	running := newFinishedJob(cj, cronJobKind, "foo-4", true, start.Add(3*time.Minute))
+	running.Status.Conditions = nil
+	tc.jobLister = append(tc.jobLister, running)
+
+	// Job that isn't owned by this HealthCheck's CronJob.
+	orphan := newFinishedJob(cj, cronJobKind, "bar-1", true, start.Add(4*time.Minute))
+	orphan.OwnerReferences = nil
+	tc.jobLister = append(tc.jobLister, orphan)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(start.Add(2 * time.Minute))
+	expected.Status.Last10 = []bool{false, true, true}
+	expected.Status.Healthy = false
+	expected.Status.AverageHealthiness = float32(2) / float32(3)
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 3), checkFailed)
+	setHistory(&expected.Status, checkResult("foo-3", false, start.Add(2*time.Minute)), checkResult("foo-2", true, start.Add(time.Minute)), checkResult("foo-1", true, start))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestRecordsOnlyNewJobResults(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lastRunTime := metav1.NewTime(start.Add(time.Minute))
+	hc.Status.CronJobName = healthCheckName
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{false, false, false, false, false, false, false, false, false, false}
+	setHistory(&hc.Status, checkResult("foo-2", false, start.Add(time.Minute)), checkResult("foo-1", false, start))
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister,
+		newFinishedJob(cj, cronJobKind, "foo-1", false, start),
+		newFinishedJob(cj, cronJobKind, "foo-2", false, start.Add(time.Minute)),
+		newFinishedJob(cj, cronJobKind, "foo-3", true, start.Add(2*time.Minute)),
+	)
+
+	expected := hc.DeepCopy()
+	newLastRunTime := metav1.NewTime(start.Add(2 * time.Minute))
+	expected.Status.Last10 = []bool{true, false, false, false, false, false, false, false, false, false}
+	expected.Status.Healthy = true
+	expected.Status.AverageHealthiness = 0.1
+	expected.Status.LastRunTime = &newLastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(9, 10), checkPassed)
+	setHistory(&expected.Status,
+		checkResult("foo-3", true, start.Add(2*time.Minute)),
+		checkResult("foo-2", false, start.Add(time.Minute)),
+		checkResult("foo-1", false, start),
+	)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestDeleteCronJob(t *testing.T) {
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", nil)
+	hc.Status.CronJobName = "foo"
+	cj := newCronJob(hc, "foo", "* * * * *", testConfig)
+
+	for _, obj := range []interface{}{hc, cache.DeletedFinalStateUnknown{Key: "default/foo", Obj: hc}} {
+		tc := newTestCase(t)
+		tc.cjLister = append(tc.cjLister, cj)
+		tc.kubeObjects = append(tc.kubeObjects, cj)
+		c, _, _ := tc.newController()
+
+		c.deleteCronJob(obj)
+		actions := filterInformerActions(tc.kubeclient.Actions())
+		if len(actions) != 1 || !actions[0].Matches("delete", "cronjobs") {
+			t.Errorf("expected the CronJob to be deleted for %T, got actions %+v", obj, actions)
+		}
+	}
+}
+
+func TestRecordsJobResultsFinishedInTheSameSecond(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Spec.ConcurrencyPolicy = batchv1beta1.AllowConcurrent
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+	lastRunTime := metav1.NewTime(testTime)
+	hc.Status.CronJobName = healthCheckName
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{true}
+	hc.Status.Healthy = true
+	hc.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	hc.Status.AverageHealthiness = 1
+	setHistory(&hc.Status, checkResult("foo-1", true, testTime))
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	// foo-2 finished in the same second as foo-1, but wasn't recorded.
+	tc.jobLister = append(tc.jobLister,
+		newFinishedJob(cj, cronJobKind, "foo-1", true, testTime),
+		newFinishedJob(cj, cronJobKind, "foo-2", true, testTime),
+	)
+
+	expected := hc.DeepCopy()
+	expected.Status.Last10 = []bool{true, true}
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 2), checkPassed)
+	setHistory(&expected.Status, checkResult("foo-2", true, testTime), checkResult("foo-1", true, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestCreatesCronJobFromFrequency(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "5m", "", nil)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	expectedCronJob := newCronJob(hc, healthCheckName, "*/5 * * * *", testConfig)
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
+	tc.expectCreateCronJobAction(expectedCronJob)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestInvalidFrequency(t *testing.T) {
+	for _, freq := range []string{"banana", "6hours"} {
+		tc := newTestCase(t)
+		hc := newHealthCheck("foo", "nginx", freq, "", nil)
+
+		tc.hcLister = append(tc.hcLister, hc)
+		tc.objects = append(tc.objects, hc)
+
+		_, err := frequency.ParseFrequency(freq)
+		msg := fmt.Sprintf(MessageInvalidFrequency, err.Error())
+		expected := hc.DeepCopy()
+		expected.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+			newCondition(healthv1alpha1.HealthCheckInvalidSpec, corev1.ConditionTrue, ErrInvalidFrequency, msg),
+			newCondition(healthv1alpha1.HealthCheckResourceConflict, corev1.ConditionFalse, ReasonNoConflict, ""),
+			newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionFalse, ErrInvalidFrequency, msg),
+			newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ErrInvalidFrequency, msg),
+		}
+		tc.expectUpdateHealthCheckStatusAction(expected, "")
+		tc.run(getKey(t, hc))
+	}
+}
+
+func TestCreatesScheduledJob(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	scheduled := scheduledTime(testTime, 30*time.Second, jitter(hc, 30*time.Second))
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(controllerScheduled("30s"), degraded(0, 0), awaitingResults)
+	tc.expectCreateJobAction(newJob(hc, fmt.Sprintf("foo-%d", scheduled.Unix()), testCheckerImage))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestScheduledJobAlreadyExists(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+	scheduled := scheduledTime(testTime, 30*time.Second, jitter(hc, 30*time.Second))
+	job := newFinishedJob(hc, healthCheckKind, fmt.Sprintf("foo-%d", scheduled.Unix()), true, testTime)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.jobLister = append(tc.jobLister, job)
+	tc.kubeObjects = append(tc.kubeObjects, job)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{true}
+	expected.Status.Healthy = true
+	expected.Status.AverageHealthiness = 1
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	expected.Status.Conditions = syncedConditions(controllerScheduled("30s"), degraded(0, 1), checkPassed)
+	setHistory(&expected.Status, checkResult(job.Name, true, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestSwitchesFromCronJobToScheduledJobs(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "90s", "", nil)
+	hc.Status.CronJobName = healthCheckName
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+	// Jobs that have already run, more than the history limit.
+	for i := 0; i < int(testConfig.SuccessfulJobsHistoryLimit)+1; i++ {
+		job := newFinishedJob(hc, healthCheckKind, fmt.Sprintf("foo-%d", i), true, testTime.Add(time.Duration(i-20)*time.Minute))
+		tc.jobLister = append(tc.jobLister, job)
+		tc.kubeObjects = append(tc.kubeObjects, job)
+	}
+	// Job that is still running.
+	running := newFinishedJob(hc, healthCheckKind, "foo-running", true, testTime)
+	running.Status.Conditions = nil
+	tc.jobLister = append(tc.jobLister, running)
+	tc.kubeObjects = append(tc.kubeObjects, running)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime.Add(-10 * time.Minute))
+	expected.Status.Last10 = []bool{true, true, true, true, true, true, true, true, true, true}
+	expected.Status.Healthy = true
+	expected.Status.AverageHealthiness = 1
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	expected.Status.Conditions = syncedConditions(controllerScheduled("90s"), degraded(0, 10), checkPassed)
+	var history []healthv1alpha1.CheckResult
+	for i := int(testConfig.SuccessfulJobsHistoryLimit); i > 0; i-- {
+		history = append(history, checkResult(fmt.Sprintf("foo-%d", i), true, testTime.Add(time.Duration(i-20)*time.Minute)))
+	}
+	setHistory(&expected.Status, history...)
+	tc.expectDeleteCronJobAction(cj)
+	tc.expectDeleteJobAction(tc.jobLister[0])
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestScheduledTime(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 47, 0, time.UTC)
+	scheduled := scheduledTime(now, 30*time.Second, 2*time.Second)
+	if expected := time.Date(2020, 1, 1, 0, 0, 32, 0, time.UTC); !scheduled.Equal(expected) {
+		t.Errorf("expected scheduled time %s but got %s", expected, scheduled)
+	}
+	scheduled = scheduledTime(now, 30*time.Second, 20*time.Second)
+	if expected := time.Date(2020, 1, 1, 0, 0, 20, 0, time.UTC); !scheduled.Equal(expected) {
+		t.Errorf("expected scheduled time %s but got %s", expected, scheduled)
+	}
+}
+
+func TestSetConditionKeepsTransitionTime(t *testing.T) {
+	tc := newTestCase(t)
+	c, _, _ := tc.newController()
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", nil)
+	before := metav1.NewTime(testTime.Add(-time.Hour))
+	hc.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+		{Type: healthv1alpha1.HealthCheckReady, Status: corev1.ConditionTrue, LastTransitionTime: before},
+		{Type: healthv1alpha1.HealthCheckDegraded, Status: corev1.ConditionFalse, LastTransitionTime: before},
+	}
+
+	c.setCondition(hc, healthv1alpha1.HealthCheckReady, corev1.ConditionTrue, ReasonCheckPassed, MessageCheckPassed)
+	c.setCondition(hc, healthv1alpha1.HealthCheckDegraded, corev1.ConditionTrue, ReasonRecentFailures, "")
+
+	if len(hc.Status.Conditions) != 2 {
+		t.Fatalf("expected 2 conditions but got %d", len(hc.Status.Conditions))
+	}
+	if ready := hc.Status.Conditions[0]; !ready.LastTransitionTime.Equal(&before) || ready.Reason != ReasonCheckPassed {
+		t.Errorf("expected unchanged Ready condition to keep its transition time, got %+v", ready)
+	}
+	if degraded := hc.Status.Conditions[1]; !degraded.LastTransitionTime.Time.Equal(testTime) {
+		t.Errorf("expected changed Degraded condition to have a new transition time, got %+v", degraded)
+	}
+}
+
+func TestRecordsObservedGeneration(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Generation = 3
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+
+	expected := hc.DeepCopy()
+	expected.Status.ObservedGeneration = 3
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
+	for i := range expected.Status.Conditions {
+		expected.Status.Conditions[i].ObservedGeneration = 3
+	}
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestCreatesHTTPProbeCronJob(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "", "", "* * * * *", nil)
+	hc.Spec.HTTP = &healthv1alpha1.HTTPProbe{URL: "http://example.com/"}
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	expectedCronJob := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	container := expectedCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+	if container.Image != testCheckerImage {
+		t.Errorf("expected checker image %q but got %q", testCheckerImage, container.Image)
+	}
+	if expectedArgs := []string{"-probe", `{"http":{"url":"http://example.com/"}}`}; !reflect.DeepEqual(container.Args, expectedArgs) {
+		t.Errorf("expected args %v but got %v", expectedArgs, container.Args)
+	}
+
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
+	tc.expectCreateCronJobAction(expectedCronJob)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestValidateSpec(t *testing.T) {
+	tt := []struct {
+		name  string
+		spec  healthv1alpha1.HealthCheckSpec
+		valid bool
+	}{
+		{name: "image", spec: healthv1alpha1.HealthCheckSpec{Image: "nginx"}, valid: true},
+		{name: "http", spec: healthv1alpha1.HealthCheckSpec{HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com"}}, valid: true},
+		{name: "tcp", spec: healthv1alpha1.HealthCheckSpec{TCP: &healthv1alpha1.TCPProbe{Host: "db", Port: 5433}}, valid: true},
+		{name: "dns", spec: healthv1alpha1.HealthCheckSpec{DNS: &healthv1alpha1.DNSProbe{Name: "example.com"}}, valid: true},
+		{name: "short_frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1s", Image: "nginx"}, valid: true},
+		{name: "zero_frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "0s", Image: "nginx"}},
+		{name: "sub_second_frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "0.001s", Image: "nginx"}},
+		{name: "nothing", spec: healthv1alpha1.HealthCheckSpec{}},
+		{name: "http_without_url", spec: healthv1alpha1.HealthCheckSpec{HTTP: &healthv1alpha1.HTTPProbe{}}},
+		{name: "tcp_without_port", spec: healthv1alpha1.HealthCheckSpec{TCP: &healthv1alpha1.TCPProbe{Host: "db"}}},
+		{name: "dns_without_name", spec: healthv1alpha1.HealthCheckSpec{DNS: &healthv1alpha1.DNSProbe{}}},
+		{name: "dns_record_type", spec: healthv1alpha1.HealthCheckSpec{DNS: &healthv1alpha1.DNSProbe{Name: "example.com", RecordType: "SRV"}}, valid: true},
+		{
+			name: "http_headers_from",
+			spec: healthv1alpha1.HealthCheckSpec{HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com", HeadersFrom: []healthv1alpha1.HTTPHeaderSource{{
+				Name:      "Authorization",
+				ValueFrom: healthv1alpha1.HTTPHeaderValueSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "api"}, Key: "token"}},
+			}}}},
+			valid: true,
+		},
+		{
+			name: "http_headers_from_without_key",
+			spec: healthv1alpha1.HealthCheckSpec{HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com", HeadersFrom: []healthv1alpha1.HTTPHeaderSource{{
+				Name:      "Authorization",
+				ValueFrom: healthv1alpha1.HTTPHeaderValueSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "api"}}},
+			}}}},
+		},
+		{name: "dns_unknown_record_type", spec: healthv1alpha1.HealthCheckSpec{DNS: &healthv1alpha1.DNSProbe{Name: "example.com", RecordType: "AAA"}}},
+		{
+			name: "steps",
+			spec: healthv1alpha1.HealthCheckSpec{Steps: []healthv1alpha1.Step{
+				{Name: "login", HTTP: healthv1alpha1.HTTPProbe{URL: "http://example.com/login"}},
+			}},
+			valid: true,
+		},
+		{
+			name: "steps_and_http",
+			spec: healthv1alpha1.HealthCheckSpec{
+				HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com"},
+				Steps: []healthv1alpha1.Step{
+					{Name: "login", HTTP: healthv1alpha1.HTTPProbe{URL: "http://example.com/login"}},
+				},
+			},
+		},
+		{
+			name: "tcp_and_dns",
+			spec: healthv1alpha1.HealthCheckSpec{
+				TCP: &healthv1alpha1.TCPProbe{Host: "db", Port: 5433},
+				DNS: &healthv1alpha1.DNSProbe{Name: "example.com"},
+			},
+		},
+		{
+			name: "template",
+			spec: healthv1alpha1.HealthCheckSpec{
+				Image: "nginx",
+				Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "healthcheck", Command: []string{"/check"}}},
+				}},
+			},
+			valid: true,
+		},
+		{
+			name: "template_with_controller_label",
+			spec: healthv1alpha1.HealthCheckSpec{
+				Image:    "nginx",
+				Template: &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{controllerLabel: "bar"}}},
+			},
+		},
+		{
+			name: "template_with_restart_policy",
+			spec: healthv1alpha1.HealthCheckSpec{
+				Image:    "nginx",
+				Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{RestartPolicy: corev1.RestartPolicyAlways}},
+			},
+		},
+		{
+			name: "template_with_owner_references",
+			spec: healthv1alpha1.HealthCheckSpec{
+				Image:    "nginx",
+				Template: &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Name: "bar"}}}},
+			},
+		},
+		{
+			name: "template_with_check_image",
+			spec: healthv1alpha1.HealthCheckSpec{
+				Image: "nginx",
+				Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "healthcheck", Image: "busybox"}},
+				}},
+			},
+		},
+		{
+			name: "template_with_probe_command",
+			spec: healthv1alpha1.HealthCheckSpec{
+				HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com"},
+				Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "healthcheck", Command: []string{"/check"}}},
+				}},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		if err := ValidateSpec(tc.spec); (err == nil) != tc.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+func TestInvalidSpec(t *testing.T) {
+	tc := newTestCase(t)
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", nil)
+	hc.Spec.HTTP = &healthv1alpha1.HTTPProbe{URL: "http://example.com/"}
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	msg := fmt.Sprintf(MessageInvalidSpec, ValidateSpec(hc.Spec).Error())
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInvalidSpec, corev1.ConditionTrue, ErrInvalidSpec, msg),
+		newCondition(healthv1alpha1.HealthCheckResourceConflict, corev1.ConditionFalse, ReasonNoConflict, ""),
+		newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionFalse, ErrInvalidSpec, msg),
+		newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ErrInvalidSpec, msg),
+	}
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestZeroFrequencyIsInvalid(t *testing.T) {
+	tc := newTestCase(t)
+	hc := newHealthCheck("foo", "nginx", "0s", "", nil)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	// No Job is created.
+	msg := fmt.Sprintf(MessageInvalidSpec, ValidateSpec(hc.Spec).Error())
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInvalidSpec, corev1.ConditionTrue, ErrInvalidSpec, msg),
+		newCondition(healthv1alpha1.HealthCheckResourceConflict, corev1.ConditionFalse, ReasonNoConflict, ""),
+		newCondition(healthv1alpha1.HealthCheckScheduled, corev1.ConditionFalse, ErrInvalidSpec, msg),
+		newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ErrInvalidSpec, msg),
+	}
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestNewJobTemplateMergesTemplate(t *testing.T) {
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", []string{"-v"})
+	hc.Spec.Template = &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "checkout"}},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "checker",
+			Containers: []corev1.Container{
+				{Name: "proxy", Image: "envoy"},
+				{Name: "healthcheck", Command: []string{"/check"}, Args: []string{"-q"}},
+			},
+		},
+	}
+
+	pod := newJobTemplate(hc, testCheckerImage).Spec.Template
+	expected := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "checkout", controllerLabel: "foo"}},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "checker",
+			RestartPolicy:      corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{Name: "proxy", Image: "envoy"},
+				{Name: "healthcheck", Image: "nginx", Command: []string{"/check"}, Args: []string{"-v"}, TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError},
+			},
+		},
+	}
+	if !reflect.DeepEqual(pod, expected) {
+		t.Errorf("expected pod template %+v but got %+v", expected, pod)
+	}
+	if hc.Spec.Template.Labels[controllerLabel] != "" {
+		t.Errorf("expected the HealthCheck's template not to be modified")
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+const (
+	// defaultHTTPMethod is the method used by HTTP probes that don't set one.
+	defaultHTTPMethod = "GET"
+	// defaultDNSRecordType is the record type looked up by DNS probes that
+	// don't set one.
+	defaultDNSRecordType = "A"
+)
+
+// SetDefaults fills in the fields of a HealthCheck spec that the controller
+// otherwise assumes defaults for, so that they are visible on the stored
+// object. cfg holds the defaults that are configurable.
+func SetDefaults(spec *healthv1alpha1.HealthCheckSpec, cfg *config.Config) {
+	if spec.Frequency == "" && spec.CronPattern == "" {
+		if spec.Pods != nil {
+			// Pods probes can't use a cronPattern.
+			spec.Frequency = defaultPodsSampleFrequency
+		} else {
+			spec.CronPattern = cfg.DefaultCronPattern
+		}
+	}
+	if spec.FailureThreshold == 0 {
+		spec.FailureThreshold = failureThreshold(*spec)
+	}
+	if spec.SuccessThreshold == 0 {
+		spec.SuccessThreshold = successThreshold(*spec)
+	}
+	if spec.HTTP != nil && spec.HTTP.Method == "" {
+		spec.HTTP.Method = defaultHTTPMethod
+	}
+	if spec.DNS != nil && spec.DNS.RecordType == "" {
+		spec.DNS.RecordType = defaultDNSRecordType
+	}
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		if c.HTTP != nil && c.HTTP.Method == "" {
+			c.HTTP.Method = defaultHTTPMethod
+		}
+		if c.DNS != nil && c.DNS.RecordType == "" {
+			c.DNS.RecordType = defaultDNSRecordType
+		}
+	}
+	if len(spec.Containers) > 0 && spec.ContainerPolicy == "" {
+		spec.ContainerPolicy = containerPolicy(*spec)
+	}
+	for i := range spec.Steps {
+		if spec.Steps[i].HTTP.Method == "" {
+			spec.Steps[i].HTTP.Method = defaultHTTPMethod
+		}
+	}
+
+	// Pods probes don't run Jobs.
+	if spec.Pods != nil {
+		return
+	}
+	if spec.ActiveDeadlineSeconds == nil {
+		spec.ActiveDeadlineSeconds = int64Ptr(activeDeadlineSeconds(*spec))
+	}
+	if spec.BackoffLimit == nil {
+		spec.BackoffLimit = int32Ptr(backoffLimit(*spec))
+	}
+	if spec.ConcurrencyPolicy == "" {
+		spec.ConcurrencyPolicy = concurrencyPolicy(*spec)
+	}
+	if spec.StartingDeadlineSeconds == nil {
+		spec.StartingDeadlineSeconds = int64Ptr(startingDeadlineSeconds(*spec))
+	}
+	if spec.SuccessfulJobsHistoryLimit == nil {
+		spec.SuccessfulJobsHistoryLimit = int32Ptr(successfulJobsHistoryLimit(*spec, cfg))
+	}
+	if spec.FailedJobsHistoryLimit == nil {
+		spec.FailedJobsHistoryLimit = int32Ptr(failedJobsHistoryLimit(*spec, cfg))
+	}
+}
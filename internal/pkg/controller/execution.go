@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+)
+
+const (
+	// defaultActiveDeadlineSeconds is how long a run may take by default
+	// before it is stopped and counted as a failure.
+	defaultActiveDeadlineSeconds = 300
+	// defaultStartingDeadlineSeconds is how late a run may start by default
+	// after its scheduled time before it is skipped.
+	defaultStartingDeadlineSeconds = 10
+
+	// jobDeadlineExceeded is the reason the Job controller gives a failed
+	// Job that ran for longer than its ActiveDeadlineSeconds.
+	jobDeadlineExceeded = "DeadlineExceeded"
+)
+
+func activeDeadlineSeconds(spec healthv1alpha1.HealthCheckSpec) int64 {
+	if spec.ActiveDeadlineSeconds == nil {
+		return defaultActiveDeadlineSeconds
+	}
+	return *spec.ActiveDeadlineSeconds
+}
+
+func backoffLimit(spec healthv1alpha1.HealthCheckSpec) int32 {
+	if spec.BackoffLimit == nil {
+		return 0
+	}
+	return *spec.BackoffLimit
+}
+
+func concurrencyPolicy(spec healthv1alpha1.HealthCheckSpec) batchv1beta1.ConcurrencyPolicy {
+	if spec.ConcurrencyPolicy == "" {
+		return batchv1beta1.ForbidConcurrent
+	}
+	return spec.ConcurrencyPolicy
+}
+
+func startingDeadlineSeconds(spec healthv1alpha1.HealthCheckSpec) int64 {
+	if spec.StartingDeadlineSeconds == nil {
+		return defaultStartingDeadlineSeconds
+	}
+	return *spec.StartingDeadlineSeconds
+}
+
+func startingDeadline(spec healthv1alpha1.HealthCheckSpec) time.Duration {
+	return time.Duration(startingDeadlineSeconds(spec)) * time.Second
+}
+
+func successfulJobsHistoryLimit(spec healthv1alpha1.HealthCheckSpec, cfg *config.Config) int32 {
+	if spec.SuccessfulJobsHistoryLimit == nil {
+		return cfg.SuccessfulJobsHistoryLimit
+	}
+	return *spec.SuccessfulJobsHistoryLimit
+}
+
+func failedJobsHistoryLimit(spec healthv1alpha1.HealthCheckSpec, cfg *config.Config) int32 {
+	if spec.FailedJobsHistoryLimit == nil {
+		return cfg.FailedJobsHistoryLimit
+	}
+	return *spec.FailedJobsHistoryLimit
+}
+
+// jobTimedOut returns true if a failed Job was stopped for running longer
+// than its ActiveDeadlineSeconds.
+func jobTimedOut(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Reason == jobDeadlineExceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// validateExecution checks the fields controlling how runs are executed.
+func validateExecution(spec healthv1alpha1.HealthCheckSpec) error {
+	if spec.ActiveDeadlineSeconds != nil && *spec.ActiveDeadlineSeconds < 1 {
+		return fmt.Errorf("activeDeadlineSeconds must be at least 1")
+	}
+	if spec.BackoffLimit != nil && *spec.BackoffLimit < 0 {
+		return fmt.Errorf("backoffLimit can't be negative")
+	}
+	switch spec.ConcurrencyPolicy {
+	case "", batchv1beta1.AllowConcurrent, batchv1beta1.ForbidConcurrent, batchv1beta1.ReplaceConcurrent:
+	default:
+		return fmt.Errorf("concurrencyPolicy must be Allow, Forbid or Replace")
+	}
+	if spec.StartingDeadlineSeconds != nil && *spec.StartingDeadlineSeconds < 0 {
+		return fmt.Errorf("startingDeadlineSeconds can't be negative")
+	}
+	// A Job must be kept until the controller has seen it finish, or its
+	// result is lost.
+	if spec.SuccessfulJobsHistoryLimit != nil && *spec.SuccessfulJobsHistoryLimit < 1 {
+		return fmt.Errorf("successfulJobsHistoryLimit must be at least 1")
+	}
+	if spec.FailedJobsHistoryLimit != nil && *spec.FailedJobsHistoryLimit < 1 {
+		return fmt.Errorf("failedJobsHistoryLimit must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewCronJobExecutionPolicy(t *testing.T) {
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", nil)
+	cj := newCronJob(hc, "foo", "* * * * *", testConfig)
+	if *cj.Spec.JobTemplate.Spec.ActiveDeadlineSeconds != defaultActiveDeadlineSeconds {
+		t.Errorf("expected default activeDeadlineSeconds %d but got %d", defaultActiveDeadlineSeconds, *cj.Spec.JobTemplate.Spec.ActiveDeadlineSeconds)
+	}
+	if cj.Spec.ConcurrencyPolicy != batchv1beta1.ForbidConcurrent {
+		t.Errorf("expected default concurrencyPolicy Forbid but got %s", cj.Spec.ConcurrencyPolicy)
+	}
+
+	hc.Spec.ActiveDeadlineSeconds = int64Ptr(30)
+	hc.Spec.BackoffLimit = int32Ptr(2)
+	hc.Spec.ConcurrencyPolicy = batchv1beta1.ReplaceConcurrent
+	hc.Spec.StartingDeadlineSeconds = int64Ptr(60)
+	hc.Spec.SuccessfulJobsHistoryLimit = int32Ptr(1)
+	hc.Spec.FailedJobsHistoryLimit = int32Ptr(3)
+	cj = newCronJob(hc, "foo", "* * * * *", testConfig)
+	switch {
+	case *cj.Spec.JobTemplate.Spec.ActiveDeadlineSeconds != 30,
+		*cj.Spec.JobTemplate.Spec.BackoffLimit != 2,
+		cj.Spec.ConcurrencyPolicy != batchv1beta1.ReplaceConcurrent,
+		*cj.Spec.StartingDeadlineSeconds != 60,
+		*cj.Spec.SuccessfulJobsHistoryLimit != 1,
+		*cj.Spec.FailedJobsHistoryLimit != 3:
+		t.Errorf("expected the CronJob to use the HealthCheck's execution policy, got %+v", cj.Spec)
+	}
+}
+
+func TestTimedOutRun(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Spec.ActiveDeadlineSeconds = int64Ptr(30)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	hc.Status.CronJobName = healthCheckName
+	job := newFinishedJob(cj, cronJobKind, "foo-1", false, testTime)
+	job.Status.Conditions[0].Reason = jobDeadlineExceeded
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, job)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{false}
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckTimedOut
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 1),
+		newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionFalse, ReasonCheckTimedOut, fmt.Sprintf(MessageCheckTimedOut, 30)))
+	setHistory(&expected.Status, checkResult("foo-1", false, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestReplacesActiveScheduledJob(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+	hc.Spec.ConcurrencyPolicy = batchv1beta1.ReplaceConcurrent
+	running := newFinishedJob(hc, healthCheckKind, "foo-running", true, testTime)
+	running.Status.Conditions = nil
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.jobLister = append(tc.jobLister, running)
+	tc.kubeObjects = append(tc.kubeObjects, running)
+
+	scheduled := scheduledTime(testTime, 30*time.Second, jitter(hc, 30*time.Second))
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(controllerScheduled("30s"), degraded(0, 0), awaitingResults)
+	tc.expectDeleteJobAction(running)
+	tc.expectCreateJobAction(newJob(hc, fmt.Sprintf("foo-%d", scheduled.Unix()), testCheckerImage))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestForbidsConcurrentScheduledJob(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+	running := newFinishedJob(hc, healthCheckKind, "foo-running", true, testTime)
+	running.Status.Conditions = nil
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.jobLister = append(tc.jobLister, running)
+	tc.kubeObjects = append(tc.kubeObjects, running)
+
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(controllerScheduled("30s"), degraded(0, 0), awaitingResults)
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestDeletesJobsBeyondHistoryLimit(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+	hc.Spec.SuccessfulJobsHistoryLimit = int32Ptr(1)
+	lastRunTime := metav1.NewTime(testTime)
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{true, true}
+	hc.Status.Healthy = true
+	hc.Status.AverageHealthiness = 1
+	scheduled := scheduledTime(testTime, 30*time.Second, jitter(hc, 30*time.Second))
+	setHistory(&hc.Status,
+		checkResult(fmt.Sprintf("foo-%d", scheduled.Unix()), true, testTime),
+		checkResult("foo-old", true, testTime.Add(-time.Minute)),
+	)
+
+	old := newFinishedJob(hc, healthCheckKind, "foo-old", true, testTime.Add(-time.Minute))
+	latest := newFinishedJob(hc, healthCheckKind, fmt.Sprintf("foo-%d", scheduled.Unix()), true, testTime)
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.jobLister = append(tc.jobLister, old, latest)
+	tc.kubeObjects = append(tc.kubeObjects, old, latest)
+
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(controllerScheduled("30s"), degraded(0, 2), checkPassed)
+	tc.expectDeleteJobAction(old)
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestValidateExecution(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  healthv1alpha1.HealthCheckSpec
+		valid bool
+	}{
+		{name: "defaults", valid: true},
+		{
+			name: "all set",
+			spec: healthv1alpha1.HealthCheckSpec{
+				ActiveDeadlineSeconds:      int64Ptr(60),
+				BackoffLimit:               int32Ptr(1),
+				ConcurrencyPolicy:          batchv1beta1.AllowConcurrent,
+				StartingDeadlineSeconds:    int64Ptr(0),
+				SuccessfulJobsHistoryLimit: int32Ptr(1),
+				FailedJobsHistoryLimit:     int32Ptr(5),
+			},
+			valid: true,
+		},
+		{name: "zero deadline", spec: healthv1alpha1.HealthCheckSpec{ActiveDeadlineSeconds: int64Ptr(0)}},
+		{name: "negative backoff", spec: healthv1alpha1.HealthCheckSpec{BackoffLimit: int32Ptr(-1)}},
+		{name: "unknown concurrency", spec: healthv1alpha1.HealthCheckSpec{ConcurrencyPolicy: "Sometimes"}},
+		{name: "negative starting deadline", spec: healthv1alpha1.HealthCheckSpec{StartingDeadlineSeconds: int64Ptr(-1)}},
+		{name: "negative history", spec: healthv1alpha1.HealthCheckSpec{FailedJobsHistoryLimit: int32Ptr(-1)}},
+		{name: "no successful history", spec: healthv1alpha1.HealthCheckSpec{SuccessfulJobsHistoryLimit: int32Ptr(0)}},
+		{name: "no failed history", spec: healthv1alpha1.HealthCheckSpec{FailedJobsHistoryLimit: int32Ptr(0)}},
+	}
+	for _, test := range tests {
+		if err := validateExecution(test.spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", test.name, test.valid, err)
+		}
+	}
+}
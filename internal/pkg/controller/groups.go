@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// MessageGroupBecameHealthy is the message used for an Event fired when a
+	// HealthCheckGroup becomes healthy.
+	MessageGroupBecameHealthy = "HealthCheckGroup is healthy, %d of %d members healthy"
+	// MessageGroupBecameUnhealthy is the message used for an Event fired when
+	// a HealthCheckGroup becomes unhealthy.
+	MessageGroupBecameUnhealthy = "HealthCheckGroup is unhealthy, %d of %d members healthy"
+)
+
+func (c *Controller) enqueueHealthCheckGroup(obj interface{}) {
+	var (
+		key string
+		err error
+	)
+
+	if key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.groupWorkqueue.Add(key)
+}
+
+// handleGroupMember enqueues the HealthCheckGroups that select a HealthCheck,
+// so that changes to a member's status or labels are reflected in its groups.
+func (c *Controller) handleGroupMember(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+
+	groups, err := c.healthcheckgroupsLister.HealthCheckGroups(object.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, group := range groups {
+		selector, err := metav1.LabelSelectorAsSelector(&group.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(object.GetLabels())) {
+			c.enqueueHealthCheckGroup(group)
+		}
+	}
+}
+
+// syncHealthCheckGroup updates a HealthCheckGroup's status from the status of
+// its members.
+func (c *Controller) syncHealthCheckGroup(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key '%s'", key))
+		return nil
+	}
+
+	group, err := c.healthcheckgroupsLister.HealthCheckGroups(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := validateGroupSpec(group.Spec); err != nil {
+		// The spec needs to change before this can succeed, so don't requeue.
+		msg := fmt.Sprintf(MessageInvalidSpec, err.Error())
+		c.recorder.Event(group, corev1.EventTypeWarning, ErrInvalidSpec, msg)
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&group.Spec.Selector)
+	if err != nil {
+		return err
+	}
+	members, err := c.healthchecksLister.HealthChecks(namespace).List(selector)
+	if err != nil {
+		return err
+	}
+
+	status := aggregateHealth(group.Spec, members)
+	status.ObservedGeneration = group.GetGeneration()
+	if reflect.DeepEqual(group.Status, status) {
+		return nil
+	}
+
+	groupCopy := group.DeepCopy()
+	groupCopy.Status = status
+	_, err = c.healthclientset.HealthV1alpha1().HealthCheckGroups(namespace).UpdateStatus(groupCopy)
+	if err != nil {
+		return err
+	}
+
+	// As with HealthChecks, a new group becoming healthy isn't reported as a
+	// recovery.
+	if status.Healthy != group.Status.Healthy && (group.Status.Members > 0 || !status.Healthy) {
+		if status.Healthy {
+			c.recorder.Eventf(group, corev1.EventTypeNormal, BecameHealthy, MessageGroupBecameHealthy, status.HealthyMembers, status.Members)
+		} else {
+			c.recorder.Eventf(group, corev1.EventTypeWarning, BecameUnhealthy, MessageGroupBecameUnhealthy, status.HealthyMembers, status.Members)
+		}
+	}
+	return nil
+}
+
+// validateGroupSpec returns an error if a HealthCheckGroupSpec is invalid.
+func validateGroupSpec(spec healthv1alpha1.HealthCheckGroupSpec) error {
+	if _, err := metav1.LabelSelectorAsSelector(&spec.Selector); err != nil {
+		return err
+	}
+	if spec.Quorum < 0 {
+		return fmt.Errorf("quorum can't be negative")
+	}
+	for name, weight := range spec.Weights {
+		if weight < 0 {
+			return fmt.Errorf("weight of member %s can't be negative", name)
+		}
+	}
+	if spec.MinScore < 0 || spec.MinScore > 1 {
+		return fmt.Errorf("minScore must be between 0 and 1")
+	}
+	return nil
+}
+
+// aggregateHealth computes the status of a HealthCheckGroup with the given
+// members.
+func aggregateHealth(spec healthv1alpha1.HealthCheckGroupSpec, members []*healthv1alpha1.HealthCheck) healthv1alpha1.HealthCheckGroupStatus {
+	status := healthv1alpha1.HealthCheckGroupStatus{
+		Members: int32(len(members)),
+	}
+
+	var totalWeight, weightedHealthiness float32
+	for _, hc := range members {
+		if hc.Status.Healthy {
+			status.HealthyMembers++
+		} else {
+			status.FailingMembers = append(status.FailingMembers, hc.GetName())
+		}
+
+		weight, ok := spec.Weights[hc.GetName()]
+		if !ok {
+			weight = 1
+		}
+		totalWeight += float32(weight)
+		weightedHealthiness += float32(weight) * hc.Status.AverageHealthiness
+	}
+	sort.Strings(status.FailingMembers)
+	if totalWeight > 0 {
+		status.Score = weightedHealthiness / totalWeight
+	} else if len(members) > 0 {
+		// Every member has a weight of 0, so they all count equally.
+		var healthiness float32
+		for _, hc := range members {
+			healthiness += hc.Status.AverageHealthiness
+		}
+		status.Score = healthiness / float32(len(members))
+	}
+
+	quorum := spec.Quorum
+	if quorum <= 0 {
+		quorum = status.Members
+	}
+	status.AllHealthy = status.Members > 0 && status.HealthyMembers == status.Members
+	status.QuorumReached = status.Members > 0 && status.HealthyMembers >= quorum
+	status.Healthy = status.QuorumReached && status.Score >= spec.MinScore
+	return status
+}
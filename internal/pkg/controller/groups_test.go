@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	core "k8s.io/client-go/testing"
+)
+
+func newHealthCheckGroup(name string, spec healthv1alpha1.HealthCheckGroupSpec) *healthv1alpha1.HealthCheckGroup {
+	return &healthv1alpha1.HealthCheckGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: spec,
+	}
+}
+
+func newGroupMember(name string, labels map[string]string, healthy bool, averageHealthiness float32) *healthv1alpha1.HealthCheck {
+	hc := newHealthCheck(name, "nginx", "1m", "", nil)
+	hc.Labels = labels
+	hc.Status.Healthy = healthy
+	hc.Status.AverageHealthiness = averageHealthiness
+	return hc
+}
+
+func (tc *testCase) expectUpdateHealthCheckGroupStatusAction(group *healthv1alpha1.HealthCheckGroup) {
+	action := core.NewUpdateAction(schema.GroupVersionResource{Resource: "healthcheckgroups"}, group.Namespace, group)
+	action.Subresource = "status"
+	tc.actions = append(tc.actions, action)
+}
+
+func TestAggregatesGroupMembers(t *testing.T) {
+	tc := newTestCase(t)
+	checkout := map[string]string{"system": "checkout"}
+	group := newHealthCheckGroup("checkout", healthv1alpha1.HealthCheckGroupSpec{
+		Selector: metav1.LabelSelector{MatchLabels: checkout},
+		Quorum:   2,
+	})
+
+	tc.groupLister = append(tc.groupLister, group)
+	tc.objects = append(tc.objects, group)
+	tc.hcLister = append(tc.hcLister,
+		newGroupMember("cart", checkout, true, 1),
+		newGroupMember("payments", checkout, false, 0.5),
+		newGroupMember("orders", checkout, true, 0.75),
+		// HealthCheck that isn't a member.
+		newGroupMember("search", map[string]string{"system": "search"}, false, 0),
+	)
+
+	expected := group.DeepCopy()
+	expected.Status = healthv1alpha1.HealthCheckGroupStatus{
+		Healthy:        true,
+		QuorumReached:  true,
+		Members:        3,
+		HealthyMembers: 2,
+		Score:          0.75,
+		FailingMembers: []string{"payments"},
+	}
+	tc.expectUpdateHealthCheckGroupStatusAction(expected)
+	tc.runGroup("default/checkout")
+}
+
+func TestGroupStatusUnchanged(t *testing.T) {
+	tc := newTestCase(t)
+	checkout := map[string]string{"system": "checkout"}
+	group := newHealthCheckGroup("checkout", healthv1alpha1.HealthCheckGroupSpec{
+		Selector: metav1.LabelSelector{MatchLabels: checkout},
+	})
+	group.Status = healthv1alpha1.HealthCheckGroupStatus{
+		Healthy:        true,
+		AllHealthy:     true,
+		QuorumReached:  true,
+		Members:        1,
+		HealthyMembers: 1,
+		Score:          1,
+	}
+
+	tc.groupLister = append(tc.groupLister, group)
+	tc.objects = append(tc.objects, group)
+	tc.hcLister = append(tc.hcLister, newGroupMember("cart", checkout, true, 1))
+
+	tc.runGroup("default/checkout")
+}
+
+func TestInvalidGroupSpec(t *testing.T) {
+	tc := newTestCase(t)
+	checkout := map[string]string{"system": "checkout"}
+	group := newHealthCheckGroup("checkout", healthv1alpha1.HealthCheckGroupSpec{
+		Selector: metav1.LabelSelector{MatchLabels: checkout},
+		Weights:  map[string]int32{"cart": -1},
+	})
+
+	tc.groupLister = append(tc.groupLister, group)
+	tc.objects = append(tc.objects, group)
+	tc.hcLister = append(tc.hcLister, newGroupMember("cart", checkout, true, 1))
+
+	// The group's status isn't updated until its spec is fixed.
+	tc.runGroup("default/checkout")
+}
+
+func TestValidateGroupSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  healthv1alpha1.HealthCheckGroupSpec
+		valid bool
+	}{
+		{
+			name:  "valid",
+			spec:  healthv1alpha1.HealthCheckGroupSpec{Quorum: 2, Weights: map[string]int32{"a": 0, "b": 3}, MinScore: 0.5},
+			valid: true,
+		},
+		{
+			name: "negative weight",
+			spec: healthv1alpha1.HealthCheckGroupSpec{Weights: map[string]int32{"a": -1}},
+		},
+		{
+			name: "negative quorum",
+			spec: healthv1alpha1.HealthCheckGroupSpec{Quorum: -1},
+		},
+		{
+			name: "minScore above 1",
+			spec: healthv1alpha1.HealthCheckGroupSpec{MinScore: 1.5},
+		},
+		{
+			name: "bad selector",
+			spec: healthv1alpha1.HealthCheckGroupSpec{Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "system", Operator: "Near"},
+			}}},
+		},
+	}
+	for _, test := range tests {
+		if err := validateGroupSpec(test.spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestAggregateHealth(t *testing.T) {
+	members := []*healthv1alpha1.HealthCheck{
+		newGroupMember("a", nil, true, 1),
+		newGroupMember("b", nil, true, 1),
+		newGroupMember("c", nil, false, 0),
+	}
+	tests := []struct {
+		name     string
+		spec     healthv1alpha1.HealthCheckGroupSpec
+		members  []*healthv1alpha1.HealthCheck
+		expected healthv1alpha1.HealthCheckGroupStatus
+	}{
+		{
+			name:     "no members",
+			expected: healthv1alpha1.HealthCheckGroupStatus{},
+		},
+		{
+			name:    "all required by default",
+			members: members,
+			expected: healthv1alpha1.HealthCheckGroupStatus{
+				Members:        3,
+				HealthyMembers: 2,
+				Score:          float32(2) / float32(3),
+				FailingMembers: []string{"c"},
+			},
+		},
+		{
+			name:    "weighted score below minimum",
+			spec:    healthv1alpha1.HealthCheckGroupSpec{Quorum: 1, Weights: map[string]int32{"c": 2}, MinScore: 0.6},
+			members: members,
+			expected: healthv1alpha1.HealthCheckGroupStatus{
+				QuorumReached:  true,
+				Members:        3,
+				HealthyMembers: 2,
+				Score:          0.5,
+				FailingMembers: []string{"c"},
+			},
+		},
+		{
+			name:    "all weights zero",
+			spec:    healthv1alpha1.HealthCheckGroupSpec{Weights: map[string]int32{"a": 0, "b": 0}},
+			members: members[:2],
+			expected: healthv1alpha1.HealthCheckGroupStatus{
+				Healthy:        true,
+				AllHealthy:     true,
+				QuorumReached:  true,
+				Members:        2,
+				HealthyMembers: 2,
+				Score:          1,
+			},
+		},
+		{
+			name:    "all healthy",
+			members: members[:2],
+			expected: healthv1alpha1.HealthCheckGroupStatus{
+				Healthy:        true,
+				AllHealthy:     true,
+				QuorumReached:  true,
+				Members:        2,
+				HealthyMembers: 2,
+				Score:          1,
+			},
+		},
+	}
+	for _, test := range tests {
+		status := aggregateHealth(test.spec, test.members)
+		if !reflect.DeepEqual(status, test.expected) {
+			t.Errorf("%s: expected status %+v but got %+v", test.name, test.expected, status)
+		}
+	}
+}
@@ -30,6 +30,28 @@ func (c *Controller) handleObject(obj interface{}) {
 	}
 	klog.V(4).Infof("Processing object '%s'", object.GetName())
 	if ownerRef := metav1.GetControllerOf(object); ownerRef != nil {
+		// Jobs spawned by a CronJob are owned by the CronJob rather than the
+		// HealthCheck, so look through to the CronJob's owner.
+		if ownerRef.Kind == "CronJob" {
+			cronjob, err := c.cronjobsLister.CronJobs(object.GetNamespace()).Get(ownerRef.Name)
+			if err != nil {
+				klog.V(4).Infof("ignoring orphaned object '%s' of CronJob '%s'", object.GetSelfLink(), ownerRef.Name)
+				return
+			}
+			c.handleObject(cronjob)
+			return
+		}
+
+		if ownerRef.Kind == clusterHealthCheckKind && c.clusterhealthchecksLister != nil {
+			chc, err := c.clusterhealthchecksLister.Get(ownerRef.Name)
+			if err != nil {
+				klog.V(4).Infof("ignoring orphaned object '%s' of ClusterHealthCheck '%s'", object.GetSelfLink(), ownerRef.Name)
+				return
+			}
+			c.enqueueHealthCheck(chc)
+			return
+		}
+
 		if ownerRef.Kind != "HealthCheck" {
 			return
 		}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"sort"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// controllerLabel is the label applied to every Job and Pod created on
+	// behalf of a HealthCheck. Its value is the name of the HealthCheck.
+	controllerLabel = "controller"
+
+	// maxResults is the number of check results kept in Last10.
+	maxResults = 10
+)
+
+// runResult is the outcome of a single finished check run, or of a single
+// sample of a Pods probe.
+type runResult struct {
+	name     string
+	passed   bool
+	finished time.Time
+	// duration is how long the run took, if known.
+	duration time.Duration
+	// timedOut is true if the run failed because it didn't finish within
+	// its deadline.
+	timedOut bool
+	// started is when the run started, if known.
+	started time.Time
+	// message and exitCode are read from the check container once it has
+	// terminated.
+	message  string
+	exitCode *int32
+	// failedStep and steps are the step results of a scripted check.
+	failedStep string
+	steps      []healthv1alpha1.StepResult
+	// containers are the results of each check container, for
+	// HealthChecks with several.
+	containers []healthv1alpha1.ContainerResult
+}
+
+// jobsForHealthCheck returns the Jobs in the HealthCheck's namespace that are
+// controlled by the given owner (the HealthCheck's CronJob).
+func (c *Controller) jobsForHealthCheck(hc *healthv1alpha1.HealthCheck, owner metav1.Object) ([]*batchv1.Job, error) {
+	selector := labels.SelectorFromSet(labels.Set{controllerLabel: objectName(hc)})
+	jobs, err := c.jobsLister.Jobs(hc.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]*batchv1.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if metav1.IsControlledBy(job, owner) {
+			owned = append(owned, job)
+		}
+	}
+	return owned, nil
+}
+
+// getJobResult classifies a Job as passed or failed. ok is false if the Job
+// has not finished yet.
+func getJobResult(job *batchv1.Job) (result runResult, ok bool) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			finished := cond.LastTransitionTime
+			if job.Status.CompletionTime != nil {
+				finished = *job.Status.CompletionTime
+			}
+			return newJobRunResult(job, true, finished.Time), true
+		case batchv1.JobFailed:
+			return newJobRunResult(job, false, cond.LastTransitionTime.Time), true
+		}
+	}
+	return runResult{}, false
+}
+
+func newJobRunResult(job *batchv1.Job, passed bool, finished time.Time) runResult {
+	result := runResult{name: job.GetName(), passed: passed, finished: finished, timedOut: !passed && jobTimedOut(job)}
+	if job.Status.StartTime != nil {
+		result.started = job.Status.StartTime.Time
+		result.duration = finished.Sub(result.started)
+	}
+	return result
+}
+
+// newJobResults returns the results of any Jobs that finished since the last
+// run recorded in the status and aren't in its history. Finish times only have
+// second precision, so Jobs that finished in the same second as the last run
+// are told apart by name.
+func newJobResults(status healthv1alpha1.HealthCheckStatus, jobs []*batchv1.Job) []runResult {
+	recorded := make(map[string]bool, len(status.History))
+	for _, result := range status.History {
+		recorded[result.JobName] = true
+	}
+	results := make([]runResult, 0, len(jobs))
+	for _, job := range jobs {
+		result, ok := getJobResult(job)
+		if !ok || recorded[result.name] {
+			continue
+		}
+		if status.LastRunTime != nil && result.finished.Before(status.LastRunTime.Time) {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// recordResults adds results to the status, keeping Last10 in reverse
+// chronological order, and recomputes Healthy and AverageHealthiness. It
+// returns true if any results were recorded.
+func recordResults(spec healthv1alpha1.HealthCheckSpec, status *healthv1alpha1.HealthCheckStatus, results []runResult) bool {
+	if len(results) == 0 {
+		return false
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].finished.Equal(results[j].finished) {
+			return results[i].name < results[j].name
+		}
+		return results[i].finished.Before(results[j].finished)
+	})
+
+	for _, result := range results {
+		status.Last10 = append([]bool{result.passed}, status.Last10...)
+		status.History = append([]healthv1alpha1.CheckResult{newCheckResult(result)}, status.History...)
+		switch {
+		case result.timedOut:
+			status.LastFailureReason = ReasonCheckTimedOut
+		case !result.passed:
+			status.LastFailureReason = ReasonCheckFailed
+		}
+	}
+	if len(status.Last10) > maxResults {
+		status.Last10 = status.Last10[:maxResults]
+	}
+	if len(status.History) > maxResults {
+		status.History = status.History[:maxResults]
+	}
+	status.LastResult = status.History[0].DeepCopy()
+
+	lastRunTime := metav1.NewTime(results[len(results)-1].finished)
+	status.LastRunTime = &lastRunTime
+	status.AverageHealthiness = averageHealthiness(status.Last10)
+	state := status.ThresholdState
+	if state == "" && status.Healthy {
+		// The status was written before ThresholdState was recorded.
+		state = healthv1alpha1.ThresholdPassing
+	}
+	status.ThresholdState, status.Healthy = evaluateHealth(spec, state, status.Last10, status.AverageHealthiness)
+	return true
+}
+
+// evaluateHealth returns the threshold state of a HealthCheck given its
+// previous state and its recent results, newest first, and whether it is
+// healthy. Like a Kubernetes probe, a passing check only starts failing after
+// FailureThreshold consecutive failures, and a failing one only starts passing
+// after SuccessThreshold consecutive passes. A new check has neither state
+// until it reaches one of the thresholds. The check is healthy if it is
+// passing and its average healthiness is at least MinAverageHealthiness.
+func evaluateHealth(spec healthv1alpha1.HealthCheckSpec, state healthv1alpha1.ThresholdState, results []bool, average float32) (healthv1alpha1.ThresholdState, bool) {
+	latest, count := consecutiveResults(results)
+	switch {
+	case count == 0:
+	case !latest && count >= failureThreshold(spec):
+		state = healthv1alpha1.ThresholdFailing
+	case latest && count >= successThreshold(spec):
+		state = healthv1alpha1.ThresholdPassing
+	}
+	return state, state == healthv1alpha1.ThresholdPassing && average >= spec.MinAverageHealthiness
+}
+
+// consecutiveResults returns the latest result, and how many results in a row
+// it has been repeated for.
+func consecutiveResults(results []bool) (latest bool, count int32) {
+	if len(results) == 0 {
+		return false, 0
+	}
+	latest = results[0]
+	for _, result := range results {
+		if result != latest {
+			break
+		}
+		count++
+	}
+	return latest, count
+}
+
+func failureThreshold(spec healthv1alpha1.HealthCheckSpec) int32 {
+	if spec.FailureThreshold <= 0 {
+		return 1
+	}
+	return spec.FailureThreshold
+}
+
+func successThreshold(spec healthv1alpha1.HealthCheckSpec) int32 {
+	if spec.SuccessThreshold <= 0 {
+		return 1
+	}
+	return spec.SuccessThreshold
+}
+
+// averageHealthiness returns the proportion of passed results.
+func averageHealthiness(results []bool) float32 {
+	if len(results) == 0 {
+		return 0
+	}
+	passed := 0
+	for _, result := range results {
+		if result {
+			passed++
+		}
+	}
+	return float32(passed) / float32(len(results))
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvaluateHealth(t *testing.T) {
+	passing, failing := healthv1alpha1.ThresholdPassing, healthv1alpha1.ThresholdFailing
+	tests := []struct {
+		name          string
+		spec          healthv1alpha1.HealthCheckSpec
+		state         healthv1alpha1.ThresholdState
+		results       []bool
+		expectedState healthv1alpha1.ThresholdState
+		expected      bool
+	}{
+		{
+			name:    "no results",
+			results: nil,
+		},
+		{
+			name:          "first pass",
+			results:       []bool{true},
+			expectedState: passing,
+			expected:      true,
+		},
+		{
+			name:          "first failure",
+			results:       []bool{false},
+			expectedState: failing,
+		},
+		{
+			name:    "new check below success threshold",
+			spec:    healthv1alpha1.HealthCheckSpec{SuccessThreshold: 3},
+			results: []bool{true},
+		},
+		{
+			name:    "new check below failure threshold",
+			spec:    healthv1alpha1.HealthCheckSpec{FailureThreshold: 3},
+			results: []bool{false},
+		},
+		{
+			name:          "single failure by default",
+			state:         passing,
+			results:       []bool{false, true},
+			expectedState: failing,
+		},
+		{
+			name:          "failure below threshold",
+			spec:          healthv1alpha1.HealthCheckSpec{FailureThreshold: 3},
+			state:         passing,
+			results:       []bool{false, false, true},
+			expectedState: passing,
+			expected:      true,
+		},
+		{
+			name:          "failure threshold reached",
+			spec:          healthv1alpha1.HealthCheckSpec{FailureThreshold: 3},
+			state:         passing,
+			results:       []bool{false, false, false, true},
+			expectedState: failing,
+		},
+		{
+			name:          "success below threshold",
+			spec:          healthv1alpha1.HealthCheckSpec{SuccessThreshold: 2},
+			state:         failing,
+			results:       []bool{true, false},
+			expectedState: failing,
+		},
+		{
+			name:          "success threshold reached",
+			spec:          healthv1alpha1.HealthCheckSpec{SuccessThreshold: 2},
+			state:         failing,
+			results:       []bool{true, true, false},
+			expectedState: passing,
+			expected:      true,
+		},
+		{
+			name:          "below minimum average healthiness",
+			spec:          healthv1alpha1.HealthCheckSpec{MinAverageHealthiness: 0.8},
+			state:         passing,
+			results:       []bool{true, false, false, true},
+			expectedState: passing,
+		},
+		{
+			// The average is applied separately from the thresholds, so
+			// the check doesn't need SuccessThreshold passes once it
+			// recovers.
+			name:          "minimum average healthiness recovered",
+			spec:          healthv1alpha1.HealthCheckSpec{SuccessThreshold: 3, MinAverageHealthiness: 0.5},
+			state:         passing,
+			results:       []bool{true, false, true},
+			expectedState: passing,
+			expected:      true,
+		},
+	}
+	for _, test := range tests {
+		state, healthy := evaluateHealth(test.spec, test.state, test.results, averageHealthiness(test.results))
+		if state != test.expectedState || healthy != test.expected {
+			t.Errorf("%s: expected state %q and healthy %t but got %q and %t", test.name, test.expectedState, test.expected, state, healthy)
+		}
+	}
+}
+
+func TestFailureThresholdNotReached(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Spec.FailureThreshold = 2
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	lastRunTime := metav1.NewTime(testTime.Add(-time.Minute))
+	hc.Status.CronJobName = healthCheckName
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{true}
+	hc.Status.Healthy = true
+	hc.Status.AverageHealthiness = 1
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", false, testTime))
+
+	expected := hc.DeepCopy()
+	newLastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{false, true}
+	expected.Status.AverageHealthiness = 0.5
+	expected.Status.LastRunTime = &newLastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 2),
+		newCondition(healthv1alpha1.HealthCheckReady, corev1.ConditionTrue, ReasonFailureThresholdNotReached, fmt.Sprintf(MessageFailureThresholdNotReached, 1, 2)))
+	setHistory(&expected.Status, checkResult("foo-1", false, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	"github.com/robfig/cron/v3"
+)
+
+// maintenanceWindow is a parsed MaintenanceWindow.
+type maintenanceWindow struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+func parseMaintenanceWindow(spec healthv1alpha1.MaintenanceWindow) (maintenanceWindow, error) {
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("invalid schedule: %s", err.Error())
+	}
+	freq, err := frequency.ParseFrequency(spec.Duration)
+	if err != nil {
+		return maintenanceWindow{}, fmt.Errorf("invalid duration: %s", err.Error())
+	}
+	location := time.UTC
+	if spec.TimeZone != "" {
+		if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return maintenanceWindow{}, fmt.Errorf("invalid timeZone: %s", err.Error())
+		}
+	}
+	return maintenanceWindow{schedule: schedule, duration: freq.ToDuration(), location: location}, nil
+}
+
+// activeUntil returns the end of the window that t falls in, if any.
+func (w maintenanceWindow) activeUntil(t time.Time) (time.Time, bool) {
+	// The window containing t is the one starting in (t-duration, t].
+	start := w.schedule.Next(t.In(w.location).Add(-w.duration))
+	if start.After(t) {
+		return time.Time{}, false
+	}
+	return start.Add(w.duration), true
+}
+
+// maintenanceWindows parses the HealthCheck's maintenance windows, skipping
+// any that are invalid. The spec is validated before it is synced.
+func maintenanceWindows(spec healthv1alpha1.HealthCheckSpec) []maintenanceWindow {
+	windows := make([]maintenanceWindow, 0, len(spec.MaintenanceWindows))
+	for _, w := range spec.MaintenanceWindows {
+		if window, err := parseMaintenanceWindow(w); err == nil {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// maintenanceUntil returns the end of the maintenance window that t falls in,
+// if any. When windows overlap, the latest end is returned.
+func maintenanceUntil(spec healthv1alpha1.HealthCheckSpec, t time.Time) (time.Time, bool) {
+	var until time.Time
+	found := false
+	for _, w := range maintenanceWindows(spec) {
+		if end, ok := w.activeUntil(t); ok && end.After(until) {
+			until = end
+			found = true
+		}
+	}
+	return until, found
+}
+
+// nextMaintenanceChange returns the next time after now that the HealthCheck
+// enters or leaves a maintenance window. ok is false if it has no windows.
+func nextMaintenanceChange(spec healthv1alpha1.HealthCheckSpec, now time.Time) (next time.Time, ok bool) {
+	for _, w := range maintenanceWindows(spec) {
+		change := w.schedule.Next(now.In(w.location))
+		if end, active := w.activeUntil(now); active && end.Before(change) {
+			change = end
+		}
+		if !ok || change.Before(next) {
+			next = change
+			ok = true
+		}
+	}
+	return next, ok
+}
+
+// runsPaused returns true if no new runs should be started at the given time,
+// because the HealthCheck is suspended or in a maintenance window.
+func runsPaused(spec healthv1alpha1.HealthCheckSpec, now time.Time) bool {
+	if spec.Suspend {
+		return true
+	}
+	_, inMaintenance := maintenanceUntil(spec, now)
+	return inMaintenance
+}
+
+// excludeMaintenance drops the results of runs that finished during a
+// maintenance window, so planned downtime doesn't count against the
+// HealthCheck.
+func excludeMaintenance(spec healthv1alpha1.HealthCheckSpec, results []runResult) []runResult {
+	if len(spec.MaintenanceWindows) == 0 {
+		return results
+	}
+	kept := make([]runResult, 0, len(results))
+	for _, result := range results {
+		if _, inMaintenance := maintenanceUntil(spec, result.finished); !inMaintenance {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+// validateMaintenanceWindows checks that every maintenance window can be
+// parsed.
+func validateMaintenanceWindows(spec healthv1alpha1.HealthCheckSpec) error {
+	for i, w := range spec.MaintenanceWindows {
+		if _, err := parseMaintenanceWindow(w); err != nil {
+			return fmt.Errorf("maintenance window %d has an %s", i, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenanceUntil(t *testing.T) {
+	// 02:00 to 04:00 London time, which is UTC+1 in July.
+	spec := healthv1alpha1.HealthCheckSpec{
+		MaintenanceWindows: []healthv1alpha1.MaintenanceWindow{
+			{Schedule: "0 2 * * *", Duration: "2h", TimeZone: "Europe/London"},
+		},
+	}
+	tests := []struct {
+		name  string
+		time  time.Time
+		until time.Time
+		ok    bool
+	}{
+		{name: "before", time: time.Date(2020, 7, 1, 0, 59, 0, 0, time.UTC)},
+		{name: "start", time: time.Date(2020, 7, 1, 1, 0, 0, 0, time.UTC), until: time.Date(2020, 7, 1, 3, 0, 0, 0, time.UTC), ok: true},
+		{name: "during", time: time.Date(2020, 7, 1, 2, 30, 0, 0, time.UTC), until: time.Date(2020, 7, 1, 3, 0, 0, 0, time.UTC), ok: true},
+		{name: "end", time: time.Date(2020, 7, 1, 3, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		until, ok := maintenanceUntil(spec, test.time)
+		if ok != test.ok || !until.Equal(test.until) {
+			t.Errorf("%s: expected (%s, %t) but got (%s, %t)", test.name, test.until, test.ok, until, ok)
+		}
+	}
+
+	next, ok := nextMaintenanceChange(spec, time.Date(2020, 7, 1, 2, 30, 0, 0, time.UTC))
+	if expected := time.Date(2020, 7, 1, 3, 0, 0, 0, time.UTC); !ok || !next.Equal(expected) {
+		t.Errorf("expected the next change at %s but got %s", expected, next)
+	}
+	next, ok = nextMaintenanceChange(spec, time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC))
+	if expected := time.Date(2020, 7, 2, 1, 0, 0, 0, time.UTC); !ok || !next.Equal(expected) {
+		t.Errorf("expected the next change at %s but got %s", expected, next)
+	}
+}
+
+func TestSuspendsCronJob(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Spec.Suspend = true
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	expectedCronJob := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	if !*expectedCronJob.Spec.Suspend {
+		t.Errorf("expected the CronJob of a suspended HealthCheck to be suspended")
+	}
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = append(syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults),
+		newCondition(healthv1alpha1.HealthCheckSuspended, corev1.ConditionTrue, ReasonSuspended, MessageSuspended))
+	tc.expectCreateCronJobAction(expectedCronJob)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestSuspendedScheduledHealthCheck(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+	hc.Spec.Suspend = true
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	// No Job is created.
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = append(syncedConditions(controllerScheduled("30s"), degraded(0, 0), awaitingResults),
+		newCondition(healthv1alpha1.HealthCheckSuspended, corev1.ConditionTrue, ReasonSuspended, MessageSuspended))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestIgnoresResultsInMaintenance(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Spec.MaintenanceWindows = []healthv1alpha1.MaintenanceWindow{{Schedule: "0 0 * * *", Duration: "1h"}}
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	hc.Status.CronJobName = healthCheckName
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, newFinishedJob(cj, cronJobKind, "foo-1", false, testTime))
+
+	suspended := cj.DeepCopy()
+	suspended.Spec.Suspend = boolPtr(true)
+	expected := hc.DeepCopy()
+	until := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	expected.Status.Conditions = append(syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults),
+		newCondition(healthv1alpha1.HealthCheckInMaintenance, corev1.ConditionTrue, ReasonInMaintenance, fmt.Sprintf(MessageInMaintenance, until)))
+	tc.expectUpdateCronJobAction(suspended)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestResumesAfterMaintenance(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Spec.MaintenanceWindows = []healthv1alpha1.MaintenanceWindow{{Schedule: "0 23 * * *", Duration: "1h"}}
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	suspended := cj.DeepCopy()
+	suspended.Spec.Suspend = boolPtr(true)
+	hc.Status.CronJobName = healthCheckName
+	hc.Status.Conditions = []healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInMaintenance, corev1.ConditionTrue, ReasonInMaintenance, ""),
+	}
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, suspended)
+	tc.kubeObjects = append(tc.kubeObjects, suspended)
+
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = append([]healthv1alpha1.HealthCheckCondition{
+		newCondition(healthv1alpha1.HealthCheckInMaintenance, corev1.ConditionFalse, ReasonNoMaintenance, ""),
+	}, syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)...)
+	tc.expectUpdateCronJobAction(cj)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	tests := []struct {
+		name   string
+		window healthv1alpha1.MaintenanceWindow
+		valid  bool
+	}{
+		{name: "valid", window: healthv1alpha1.MaintenanceWindow{Schedule: "0 2 * * 0", Duration: "2h", TimeZone: "America/New_York"}, valid: true},
+		{name: "bad schedule", window: healthv1alpha1.MaintenanceWindow{Schedule: "sometimes", Duration: "2h"}},
+		{name: "bad duration", window: healthv1alpha1.MaintenanceWindow{Schedule: "0 2 * * 0", Duration: "a while"}},
+		{name: "bad time zone", window: healthv1alpha1.MaintenanceWindow{Schedule: "0 2 * * 0", Duration: "2h", TimeZone: "Mars/Olympus_Mons"}},
+	}
+	for _, test := range tests {
+		spec := healthv1alpha1.HealthCheckSpec{MaintenanceWindows: []healthv1alpha1.MaintenanceWindow{test.window}}
+		if err := validateMaintenanceWindows(spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid to be %t, got error %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestNoAlertsInMaintenance(t *testing.T) {
+	tc := newTestCase(t)
+	hc := newHealthCheck("foo", "nginx", "", "* * * * *", nil)
+	hc.Spec.MaintenanceWindows = []healthv1alpha1.MaintenanceWindow{{Schedule: "0 0 * * *", Duration: "1h"}}
+	hc.Spec.Alerting = &healthv1alpha1.Alerting{
+		Receivers: []healthv1alpha1.AlertReceiver{{Name: "ops", Type: healthv1alpha1.AlertReceiverWebhook, URL: "http://example.com"}},
+	}
+	lastRunTime := metav1.NewTime(testTime.Add(-time.Hour))
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{false}
+
+	c, _, _ := tc.newController()
+	c.sendAlerts(hc, c.planAlerts(hc))
+	if len(tc.notifier.sent) != 0 {
+		t.Errorf("expected no alerts during maintenance, got %v", tc.notifier.sent)
+	}
+}
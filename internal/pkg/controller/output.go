@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/checker"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+const (
+	// maxMessageLength bounds the size of each message kept in a
+	// HealthCheck's status.
+	maxMessageLength = 1024
+	// jobNameLabel is the label the Job controller gives a Job's Pods.
+	jobNameLabel = "job-name"
+)
+
+// readCheckOutput fills in the message and exit code of each result from the
+// check containers of its Job's Pods. Pods that have already been deleted are
+// skipped.
+func (c *Controller) readCheckOutput(hc *healthv1alpha1.HealthCheck, results []runResult) {
+	for i := range results {
+		if results[i].name == "" {
+			continue
+		}
+		selector := labels.SelectorFromSet(labels.Set{jobNameLabel: results[i].name})
+		pods, err := c.podsLister.Pods(hc.GetNamespace()).List(selector)
+		if err != nil {
+			klog.Warningf("Couldn't list Pods of Job '%s': %s", results[i].name, err.Error())
+			continue
+		}
+		if len(hc.Spec.Containers) > 0 {
+			readContainerResults(hc.Spec, pods, &results[i])
+			continue
+		}
+		if state := lastTerminatedCheck(pods); state != nil {
+			exitCode := state.ExitCode
+			results[i].exitCode = &exitCode
+			results[i].message = checkMessage(state.Message)
+			results[i].failedStep, results[i].steps = checkSteps(state.Message)
+		}
+	}
+}
+
+// lastTerminatedCheck returns the state of the check container that finished
+// most recently, as a Job retrying a failed run has one Pod per attempt.
+func lastTerminatedCheck(pods []*corev1.Pod) *corev1.ContainerStateTerminated {
+	var last *corev1.ContainerStateTerminated
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			state := status.State.Terminated
+			if status.Name != checkContainerName || state == nil {
+				continue
+			}
+			if last == nil || state.FinishedAt.After(last.FinishedAt.Time) {
+				last = state
+			}
+		}
+	}
+	return last
+}
+
+// readContainerResults fills in the result of each check container that ran
+// in the Job's latest attempt. The run's message and exit code are those of
+// the first container that failed.
+func readContainerResults(spec healthv1alpha1.HealthCheckSpec, pods []*corev1.Pod, result *runResult) {
+	pod := lastAttempt(spec, pods)
+	if pod == nil {
+		return
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	failed := -1
+	for _, c := range spec.Containers {
+		for _, status := range statuses {
+			state := status.State.Terminated
+			if status.Name != c.Name || state == nil {
+				continue
+			}
+			result.containers = append(result.containers, healthv1alpha1.ContainerResult{
+				Name:     c.Name,
+				Passed:   state.ExitCode == 0,
+				Message:  checkMessage(state.Message),
+				ExitCode: state.ExitCode,
+			})
+			if failed < 0 && state.ExitCode != 0 {
+				failed = len(result.containers) - 1
+			}
+		}
+	}
+	if len(result.containers) == 0 {
+		return
+	}
+
+	exitCode := int32(0)
+	result.message = fmt.Sprintf("%d of %d containers passed", len(result.containers), len(spec.Containers))
+	if failed >= 0 {
+		c := result.containers[failed]
+		exitCode = c.ExitCode
+		result.message = truncateMessage(fmt.Sprintf("container %s failed: %s", c.Name, c.Message))
+	}
+	result.exitCode = &exitCode
+}
+
+// lastAttempt returns the Pod whose check containers finished most recently,
+// as a Job retrying a failed run has one Pod per attempt.
+func lastAttempt(spec healthv1alpha1.HealthCheckSpec, pods []*corev1.Pod) *corev1.Pod {
+	names := make(map[string]bool, len(spec.Containers))
+	for _, c := range spec.Containers {
+		names[c.Name] = true
+	}
+	var last *corev1.Pod
+	var lastFinished metav1.Time
+	for _, pod := range pods {
+		for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+			for _, status := range statuses {
+				state := status.State.Terminated
+				if !names[status.Name] || state == nil {
+					continue
+				}
+				if last == nil || state.FinishedAt.After(lastFinished.Time) {
+					last = pod
+					lastFinished = state.FinishedAt
+				}
+			}
+		}
+	}
+	return last
+}
+
+// checkMessage returns the message of the built-in checker's result if the
+// termination message is one, or else the whole termination message, bounded
+// to maxMessageLength.
+func checkMessage(terminationMessage string) string {
+	message := terminationMessage
+	var result checker.Result
+	if err := json.Unmarshal([]byte(terminationMessage), &result); err == nil && result.Message != "" {
+		message = result.Message
+	}
+	return truncateMessage(message)
+}
+
+// checkSteps returns the step results of a scripted check from the built-in
+// checker's result, with each message bounded to maxMessageLength.
+func checkSteps(terminationMessage string) (string, []healthv1alpha1.StepResult) {
+	var result checker.Result
+	if err := json.Unmarshal([]byte(terminationMessage), &result); err != nil {
+		return "", nil
+	}
+	for i := range result.Steps {
+		result.Steps[i].Message = truncateMessage(result.Steps[i].Message)
+	}
+	return result.FailedStep, result.Steps
+}
+
+// truncateMessage shortens a message to at most maxMessageLength bytes
+// without splitting a UTF-8 character.
+func truncateMessage(message string) string {
+	if len(message) <= maxMessageLength {
+		return message
+	}
+	const ellipsis = "..."
+	end := maxMessageLength - len(ellipsis)
+	for end > 0 && !utf8.RuneStart(message[end]) {
+		end--
+	}
+	return message[:end] + ellipsis
+}
+
+// newCheckResult returns the status entry recording a run.
+func newCheckResult(result runResult) healthv1alpha1.CheckResult {
+	checkResult := healthv1alpha1.CheckResult{
+		JobName:              result.name,
+		Passed:               result.passed,
+		Message:              result.message,
+		ExitCode:             result.exitCode,
+		DurationMilliseconds: int64(result.duration / time.Millisecond),
+		FinishTime:           metav1.NewTime(result.finished),
+		FailedStep:           result.failedStep,
+		Steps:                result.steps,
+		Containers:           result.containers,
+	}
+	if !result.started.IsZero() {
+		started := metav1.NewTime(result.started)
+		checkResult.StartTime = &started
+	}
+	return checkResult
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func checkResult(jobName string, passed bool, finished time.Time) healthv1alpha1.CheckResult {
+	return healthv1alpha1.CheckResult{JobName: jobName, Passed: passed, FinishTime: metav1.NewTime(finished)}
+}
+
+// setHistory sets the recorded runs in the status, newest first.
+func setHistory(status *healthv1alpha1.HealthCheckStatus, history ...healthv1alpha1.CheckResult) {
+	status.History = history
+	status.LastResult = history[0].DeepCopy()
+}
+
+func newCheckPod(name, jobName string, exitCode int32, message string, finished time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{jobNameLabel: jobName},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: checkContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   exitCode,
+					Message:    message,
+					FinishedAt: metav1.NewTime(finished),
+				}},
+			}},
+		},
+	}
+}
+
+func TestRecordsCheckOutput(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+	hc.Status.CronJobName = healthCheckName
+
+	started := metav1.NewTime(testTime.Add(-2 * time.Second))
+	job := newFinishedJob(cj, cronJobKind, "foo-1", false, testTime)
+	job.Status.StartTime = &started
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+	tc.jobLister = append(tc.jobLister, job)
+	// The Job was retried, and the last attempt's output is recorded.
+	tc.podLister = append(tc.podLister,
+		newCheckPod("foo-1-a", "foo-1", 2, "first attempt", testTime.Add(-time.Second)),
+		newCheckPod("foo-1-b", "foo-1", 1, `{"passed":false,"message":"GET http://example.com returned unexpected status 503"}`, testTime),
+	)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	exitCode := int32(1)
+	expected.Status.Last10 = []bool{false}
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	setHistory(&expected.Status, healthv1alpha1.CheckResult{
+		JobName:              "foo-1",
+		Message:              "GET http://example.com returned unexpected status 503",
+		ExitCode:             &exitCode,
+		DurationMilliseconds: 2000,
+		StartTime:            &started,
+		FinishTime:           lastRunTime,
+	})
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(1, 1), checkFailed)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	status := healthv1alpha1.HealthCheckStatus{}
+	for i := 0; i < maxResults+2; i++ {
+		recordResults(healthv1alpha1.HealthCheckSpec{}, &status, []runResult{{name: "run", passed: true, finished: testTime.Add(time.Duration(i) * time.Minute)}})
+	}
+	if len(status.History) != maxResults {
+		t.Errorf("expected %d results in history but got %d", maxResults, len(status.History))
+	}
+	if !status.LastResult.FinishTime.Equal(&status.History[0].FinishTime) {
+		t.Errorf("expected the last result to be the newest in history")
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	result := `{"passed":true,"message":"lookup of example.com returned 1.2.3.4"}`
+	if message := checkMessage(result); message != "lookup of example.com returned 1.2.3.4" {
+		t.Errorf("expected the checker's message but got %q", message)
+	}
+	if message := checkMessage("connection refused"); message != "connection refused" {
+		t.Errorf("expected the termination message but got %q", message)
+	}
+
+	long := strings.Repeat("é", maxMessageLength)
+	message := checkMessage(long)
+	if len(message) > maxMessageLength || !utf8.ValidString(message) || !strings.HasSuffix(message, "...") {
+		t.Errorf("expected a valid truncated message of at most %d bytes, got %d bytes", maxMessageLength, len(message))
+	}
+}
+
+func TestCheckSteps(t *testing.T) {
+	output := `{"passed":false,"message":"step profile failed: GET http://example.com/me returned unexpected status 401","failedStep":"profile","steps":[` +
+		`{"name":"login","passed":true,"message":"POST http://example.com/login returned status 200","statusCode":200,"durationMilliseconds":12},` +
+		`{"name":"profile","passed":false,"message":"GET http://example.com/me returned unexpected status 401","statusCode":401,"durationMilliseconds":3}]}`
+	failedStep, steps := checkSteps(output)
+	if failedStep != "profile" {
+		t.Errorf("expected the failed step to be profile but got %q", failedStep)
+	}
+	expected := []healthv1alpha1.StepResult{
+		{Name: "login", Passed: true, Message: "POST http://example.com/login returned status 200", StatusCode: 200, DurationMilliseconds: 12},
+		{Name: "profile", Message: "GET http://example.com/me returned unexpected status 401", StatusCode: 401, DurationMilliseconds: 3},
+	}
+	if !reflect.DeepEqual(steps, expected) {
+		t.Errorf("expected steps %+v but got %+v", expected, steps)
+	}
+
+	if failedStep, steps := checkSteps("connection refused"); failedStep != "" || steps != nil {
+		t.Errorf("expected no steps for a plain termination message, got %q %+v", failedStep, steps)
+	}
+}
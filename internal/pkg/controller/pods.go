@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultPodsSampleFrequency is how often Pods are sampled when a HealthCheck
+// doesn't set a frequency.
+const defaultPodsSampleFrequency = "1m"
+
+// syncPodsHealthCheck samples the Pods selected by a HealthCheck's Pods probe
+// once per interval, recording each sample as a check run, then requeues the
+// HealthCheck for its next sample.
+func (c *Controller) syncPodsHealthCheck(key string, hc *healthv1alpha1.HealthCheck) error {
+	freqString := hc.Spec.Frequency
+	if freqString == "" {
+		freqString = defaultPodsSampleFrequency
+	}
+	freq, err := frequency.ParseFrequency(freqString)
+	if err != nil {
+		// The spec needs to change before this can succeed, so don't requeue.
+		msg := fmt.Sprintf(MessageInvalidFrequency, err.Error())
+		c.recorder.Event(eventObject(hc), corev1.EventTypeWarning, ErrInvalidFrequency, msg)
+		return c.updateStatusForSyncError(hc, healthv1alpha1.HealthCheckInvalidSpec, ErrInvalidFrequency, msg)
+	}
+	// ValidateSpec rejects shorter frequencies, but never resample in a loop.
+	interval := clampInterval(freq.ToDuration())
+
+	// Remove any CronJob left over from when the HealthCheck ran an image or
+	// probe.
+	if err := c.deleteOwnedCronJob(hc); err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	next := now
+	if hc.Status.LastRunTime != nil {
+		next = hc.Status.LastRunTime.Add(interval)
+	}
+	runNow := runNowRequested(hc)
+	if runNow {
+		if hc, err = c.clearRunNow(hc); err != nil {
+			return err
+		}
+		next = now
+	}
+
+	healthcheck := hc
+	var results []runResult
+	if !now.Before(next) && runsPaused(hc.Spec, now) && !runNow {
+		// Skip this sample, but keep sampling at the same frequency.
+		next = now.Add(interval)
+	} else if !now.Before(next) {
+		pods, err := c.podsForHealthCheck(hc)
+		if err != nil {
+			return err
+		}
+		// Restarts before the first sample aren't counted against it.
+		var lastRestarts *int32
+		if hc.Status.LastRunTime != nil {
+			lastRestarts = &hc.Status.ObservedRestarts
+		}
+		passed, restarts := samplePods(hc.Spec.Pods, lastRestarts, pods)
+		results = append(results, runResult{passed: passed, finished: now})
+		healthcheck = hc.DeepCopy()
+		healthcheck.Status.ObservedRestarts = restarts
+		next = now.Add(interval)
+	}
+
+	if err := c.updateHealthCheckStatus(healthcheck, "", results, ReasonPodsObserved, fmt.Sprintf(MessagePodsObserved, freq)); err != nil {
+		return err
+	}
+
+	c.workqueue.AddAfter(key, next.Sub(now))
+	c.recorder.Event(eventObject(hc), corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+	return nil
+}
+
+// podsForHealthCheck returns the Pods selected by the HealthCheck's Pods probe.
+func (c *Controller) podsForHealthCheck(hc *healthv1alpha1.HealthCheck) ([]*corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&hc.Spec.Pods.Selector)
+	if err != nil {
+		return nil, err
+	}
+	return c.podsLister.Pods(hc.GetNamespace()).List(selector)
+}
+
+// samplePods returns whether the Pods pass the probe, and their total number
+// of container restarts. A sample fails if fewer Pods are ready than the probe
+// requires, or if the Pods restarted more than allowed since the previous
+// total, lastRestarts, was observed.
+func samplePods(probe *healthv1alpha1.PodsProbe, lastRestarts *int32, pods []*corev1.Pod) (bool, int32) {
+	var ready, restarts int32
+	for _, pod := range pods {
+		if isPodReady(pod) {
+			ready++
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			restarts += status.RestartCount
+		}
+	}
+
+	minReady := probe.MinReady
+	if minReady <= 0 {
+		minReady = int32(len(pods))
+		if minReady == 0 {
+			minReady = 1
+		}
+	}
+
+	// Restart counts start again when Pods are replaced, so a drop in the
+	// total isn't counted.
+	var newRestarts int32
+	if lastRestarts != nil && restarts > *lastRestarts {
+		newRestarts = restarts - *lastRestarts
+	}
+	return ready >= minReady && newRestarts <= probe.MaxRestarts, restarts
+}
+
+// isPodReady returns true if the Pod's Ready condition is true.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPodsHealthCheck(name string, probe healthv1alpha1.PodsProbe) *healthv1alpha1.HealthCheck {
+	hc := newHealthCheck(name, "", "1m", "", nil)
+	hc.Spec.Pods = &probe
+	return hc
+}
+
+func newPod(name string, labels map[string]string, ready bool, restarts int32) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: status},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", RestartCount: restarts},
+			},
+		},
+	}
+}
+
+func TestSamplesPods(t *testing.T) {
+	tc := newTestCase(t)
+	app := map[string]string{"app": "web"}
+	hc := newPodsHealthCheck("foo", healthv1alpha1.PodsProbe{
+		Selector: metav1.LabelSelector{MatchLabels: app},
+	})
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.podLister = append(tc.podLister,
+		newPod("web-1", app, true, 3),
+		newPod("web-2", app, true, 0),
+		// Pod that isn't selected.
+		newPod("db-1", map[string]string{"app": "db"}, false, 0),
+	)
+
+	expected := hc.DeepCopy()
+	lastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{true}
+	expected.Status.Healthy = true
+	expected.Status.AverageHealthiness = 1
+	expected.Status.LastRunTime = &lastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdPassing
+	expected.Status.ObservedRestarts = 3
+	expected.Status.Conditions = syncedConditions(podsObserved("1m"), degraded(0, 1), checkPassed)
+	setHistory(&expected.Status, checkResult("", true, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestSamplesPodsCountsNewRestarts(t *testing.T) {
+	tc := newTestCase(t)
+	app := map[string]string{"app": "web"}
+	hc := newPodsHealthCheck("foo", healthv1alpha1.PodsProbe{
+		Selector:    metav1.LabelSelector{MatchLabels: app},
+		MaxRestarts: 1,
+	})
+	lastRunTime := metav1.NewTime(testTime.Add(-time.Minute))
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{true}
+	hc.Status.Healthy = true
+	hc.Status.ObservedRestarts = 3
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.podLister = append(tc.podLister,
+		newPod("web-1", app, true, 5),
+		newPod("web-2", app, true, 0),
+	)
+
+	expected := hc.DeepCopy()
+	newLastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{false, true}
+	expected.Status.Healthy = false
+	expected.Status.AverageHealthiness = 0.5
+	expected.Status.LastRunTime = &newLastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.ObservedRestarts = 5
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	expected.Status.Conditions = syncedConditions(podsObserved("1m"), degraded(1, 2), checkFailed)
+	setHistory(&expected.Status, checkResult("", false, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestSamplesPodsOncePerInterval(t *testing.T) {
+	tc := newTestCase(t)
+	app := map[string]string{"app": "web"}
+	hc := newPodsHealthCheck("foo", healthv1alpha1.PodsProbe{
+		Selector: metav1.LabelSelector{MatchLabels: app},
+	})
+	lastRunTime := metav1.NewTime(testTime.Add(-30 * time.Second))
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{true}
+	hc.Status.Healthy = true
+	hc.Status.AverageHealthiness = 1
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.podLister = append(tc.podLister, newPod("web-1", app, false, 0))
+
+	expected := hc.DeepCopy()
+	expected.Status.Conditions = syncedConditions(podsObserved("1m"), degraded(0, 1), checkPassed)
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestPodsZeroFrequencyIsInvalid(t *testing.T) {
+	hc := newPodsHealthCheck("foo", healthv1alpha1.PodsProbe{
+		Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+	})
+	hc.Spec.Frequency = "0s"
+	if err := ValidateSpec(hc.Spec); err == nil {
+		t.Errorf("expected a Pods probe sampled every 0s to be invalid")
+	}
+}
+
+func TestSamplePods(t *testing.T) {
+	three := int32(3)
+	tests := []struct {
+		name         string
+		probe        healthv1alpha1.PodsProbe
+		lastRestarts *int32
+		pods         []*corev1.Pod
+		passed       bool
+	}{
+		{
+			name:   "no pods",
+			passed: false,
+		},
+		{
+			name:   "all ready",
+			pods:   []*corev1.Pod{newPod("a", nil, true, 0), newPod("b", nil, true, 0)},
+			passed: true,
+		},
+		{
+			name:   "one not ready",
+			pods:   []*corev1.Pod{newPod("a", nil, true, 0), newPod("b", nil, false, 0)},
+			passed: false,
+		},
+		{
+			name:   "enough ready",
+			probe:  healthv1alpha1.PodsProbe{MinReady: 1},
+			pods:   []*corev1.Pod{newPod("a", nil, true, 0), newPod("b", nil, false, 0)},
+			passed: true,
+		},
+		{
+			name:         "restarted",
+			lastRestarts: &three,
+			pods:         []*corev1.Pod{newPod("a", nil, true, 4)},
+			passed:       false,
+		},
+		{
+			name:         "pods replaced",
+			lastRestarts: &three,
+			pods:         []*corev1.Pod{newPod("a", nil, true, 0)},
+			passed:       true,
+		},
+	}
+	for _, test := range tests {
+		passed, _ := samplePods(&test.probe, test.lastRestarts, test.pods)
+		if passed != test.passed {
+			t.Errorf("%s: expected passed to be %t but got %t", test.name, test.passed, passed)
+		}
+	}
+}
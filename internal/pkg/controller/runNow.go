@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// RunTriggered is used as part of the Event 'reason' when a run is
+	// started because of the run-now annotation.
+	RunTriggered = "RunTriggered"
+	// MessageRunTriggered is the message used for an Event fired when a run
+	// is started because of the run-now annotation.
+	MessageRunTriggered = "Started Job %q as requested by the " + healthv1alpha1.RunNowAnnotation + " annotation"
+)
+
+// runNowRequested returns true if the HealthCheck or ClusterHealthCheck has
+// the run-now annotation.
+func runNowRequested(hc metav1.Object) bool {
+	_, ok := hc.GetAnnotations()[healthv1alpha1.RunNowAnnotation]
+	return ok
+}
+
+// manualJobName returns the name of a Job started by the run-now annotation.
+func manualJobName(hc *healthv1alpha1.HealthCheck, now time.Time) string {
+	return fmt.Sprintf("%s-manual-%d", objectName(hc), now.Unix())
+}
+
+// triggerRun creates a one-off Job for the run-now annotation, then removes
+// the annotation. It returns the updated HealthCheck and the created Job.
+func (c *Controller) triggerRun(hc *healthv1alpha1.HealthCheck, job *batchv1.Job) (*healthv1alpha1.HealthCheck, *batchv1.Job, error) {
+	klog.V(4).Infof("Creating Job '%s' to run HealthCheck '%s' now", job.GetName(), hc.GetName())
+	created, err := c.kubeclientset.BatchV1().Jobs(hc.GetNamespace()).Create(job)
+	if errors.IsAlreadyExists(err) {
+		created, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	c.recorder.Eventf(eventObject(hc), corev1.EventTypeNormal, RunTriggered, MessageRunTriggered, job.GetName())
+
+	// The annotation is only removed once the Job exists, so a request is
+	// never lost, although it may start a second run if removing it fails.
+	updated, err := c.clearRunNow(hc)
+	return updated, created, err
+}
+
+// clearRunNow removes the run-now annotation from the HealthCheck.
+func (c *Controller) clearRunNow(hc *healthv1alpha1.HealthCheck) (*healthv1alpha1.HealthCheck, error) {
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, healthv1alpha1.RunNowAnnotation))
+	return c.patch(hc, patch)
+}
+
+// newManualJob returns a Job made from a CronJob's JobTemplate, like
+// `kubectl create job --from=cronjob`. It is owned by the CronJob so that its
+// result is recorded like those of scheduled runs.
+func newManualJob(cronjob *batchv1beta1.CronJob, name string) *batchv1.Job {
+	template := cronjob.Spec.JobTemplate.DeepCopy()
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   cronjob.GetNamespace(),
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronjob, batchv1beta1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: template.Spec,
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+)
+
+func (tc *testCase) expectClearRunNowAction(hc *healthv1alpha1.HealthCheck) {
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, healthv1alpha1.RunNowAnnotation))
+	tc.actions = append(tc.actions, core.NewPatchAction(schema.GroupVersionResource{Resource: "healthchecks"}, hc.Namespace, hc.Name, types.MergePatchType, patch))
+}
+
+// requestRunNow adds the run-now annotation to the HealthCheck, and returns
+// the HealthCheck as it is once the controller has removed it again.
+func requestRunNow(hc *healthv1alpha1.HealthCheck) *healthv1alpha1.HealthCheck {
+	cleared := hc.DeepCopy()
+	cleared.Annotations = map[string]string{}
+	hc.Annotations = map[string]string{healthv1alpha1.RunNowAnnotation: "true"}
+	return cleared
+}
+
+func TestRunNowFromCronJob(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "", "* * * * *", nil)
+	hc.Status.CronJobName = healthCheckName
+	cleared := requestRunNow(hc)
+	cj := newCronJob(hc, healthCheckName, "* * * * *", testConfig)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.cjLister = append(tc.cjLister, cj)
+	tc.kubeObjects = append(tc.kubeObjects, cj)
+
+	job := newManualJob(cj, fmt.Sprintf("foo-manual-%d", testTime.Unix()))
+	if !metav1.IsControlledBy(job, cj) {
+		t.Errorf("expected the Job to be owned by the CronJob")
+	}
+	expected := cleared.DeepCopy()
+	expected.Status.Conditions = syncedConditions(cronJobScheduled(healthCheckName), degraded(0, 0), awaitingResults)
+	tc.expectCreateJobAction(job)
+	tc.expectClearRunNowAction(hc)
+	tc.expectUpdateHealthCheckStatusAction(expected, healthCheckName)
+	tc.run(getKey(t, hc))
+}
+
+func TestRunNowScheduledByController(t *testing.T) {
+	tc := newTestCase(t)
+	healthCheckName := "foo"
+	hc := newHealthCheck(healthCheckName, "nginx", "30s", "", nil)
+	// Runs are started even when the HealthCheck is suspended.
+	hc.Spec.Suspend = true
+	hc.Spec.ConcurrencyPolicy = batchv1beta1.AllowConcurrent
+	cleared := requestRunNow(hc)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	expected := cleared.DeepCopy()
+	expected.Status.Conditions = append(syncedConditions(controllerScheduled("30s"), degraded(0, 0), awaitingResults),
+		newCondition(healthv1alpha1.HealthCheckSuspended, corev1.ConditionTrue, ReasonSuspended, MessageSuspended))
+	tc.expectCreateJobAction(newJob(hc, fmt.Sprintf("foo-manual-%d", testTime.Unix()), testCheckerImage))
+	tc.expectClearRunNowAction(hc)
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
+
+func TestRunNowSamplesPods(t *testing.T) {
+	tc := newTestCase(t)
+	app := map[string]string{"app": "web"}
+	hc := newPodsHealthCheck("foo", healthv1alpha1.PodsProbe{
+		Selector: metav1.LabelSelector{MatchLabels: app},
+	})
+	// The next sample isn't due for another 30 seconds.
+	lastRunTime := metav1.NewTime(testTime.Add(-30 * time.Second))
+	hc.Status.LastRunTime = &lastRunTime
+	hc.Status.Last10 = []bool{true}
+	hc.Status.Healthy = true
+	hc.Status.AverageHealthiness = 1
+	cleared := requestRunNow(hc)
+
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+	tc.podLister = append(tc.podLister, newPod("web-1", app, false, 0))
+
+	expected := cleared.DeepCopy()
+	newLastRunTime := metav1.NewTime(testTime)
+	expected.Status.Last10 = []bool{false, true}
+	expected.Status.Healthy = false
+	expected.Status.AverageHealthiness = 0.5
+	expected.Status.LastRunTime = &newLastRunTime
+	expected.Status.ThresholdState = healthv1alpha1.ThresholdFailing
+	expected.Status.LastFailureReason = ReasonCheckFailed
+	expected.Status.Conditions = syncedConditions(podsObserved("1m"), degraded(1, 2), checkFailed)
+	tc.expectClearRunNowAction(hc)
+	setHistory(&expected.Status, checkResult("", false, testTime))
+	tc.expectUpdateHealthCheckStatusAction(expected, "")
+	tc.run(getKey(t, hc))
+}
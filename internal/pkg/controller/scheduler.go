@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// jitterDivisor bounds the per-HealthCheck offset applied to scheduled
+	// times to the interval divided by it, a tenth of the interval.
+	jitterDivisor = 10
+)
+
+// syncScheduledHealthCheck runs a HealthCheck whose frequency can't be
+// expressed as a cron schedule by creating its Jobs directly, then requeues
+// the HealthCheck for its next scheduled run.
+//
+// Jobs are named after the time they were scheduled for, so if more than one
+// controller tries to start the same run only one Job is created.
+func (c *Controller) syncScheduledHealthCheck(key string, hc *healthv1alpha1.HealthCheck) error {
+	freq, err := frequency.ParseFrequency(hc.Spec.Frequency)
+	if err != nil {
+		return err
+	}
+	interval := clampInterval(freq.ToDuration())
+
+	// Remove any CronJob left over from when the HealthCheck had a cron
+	// compatible frequency.
+	if err := c.deleteOwnedCronJob(hc); err != nil {
+		return err
+	}
+
+	jobs, err := c.jobsForHealthCheck(hc, hc)
+	if err != nil {
+		return err
+	}
+
+	now := c.clock.Now()
+	if runNowRequested(hc) {
+		var job *batchv1.Job
+		hc, job, err = c.triggerRun(hc, newJob(hc, manualJobName(hc, now), c.config.CheckerImage))
+		if err != nil {
+			return err
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	scheduled := scheduledTime(now, interval, jitter(hc, interval))
+	if now.Sub(scheduled) <= startingDeadline(hc.Spec) && !runsPaused(hc.Spec, now) {
+		if jobs, err = c.startScheduledRun(hc, jobs, scheduled); err != nil {
+			return err
+		}
+	}
+
+	if err := c.deleteOldJobs(hc, jobs); err != nil {
+		return err
+	}
+
+	results := newJobResults(hc.Status, jobs)
+	if err := c.updateHealthCheckStatus(hc, "", results, ReasonControllerScheduled, fmt.Sprintf(MessageControllerScheduled, freq)); err != nil {
+		return err
+	}
+
+	c.workqueue.AddAfter(key, scheduled.Add(interval).Sub(now))
+	c.recorder.Event(eventObject(hc), corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+	return nil
+}
+
+// clampInterval returns the interval, or MinInterval if it is shorter.
+// ValidateSpec rejects such frequencies, but a HealthCheck that got past it
+// mustn't requeue itself in a loop.
+func clampInterval(interval time.Duration) time.Duration {
+	if interval < MinInterval {
+		return MinInterval
+	}
+	return interval
+}
+
+// startScheduledRun creates the Job for the run scheduled at the given time,
+// following the HealthCheck's concurrency policy for any earlier runs that
+// haven't finished. It returns the HealthCheck's Jobs after any changes.
+func (c *Controller) startScheduledRun(hc *healthv1alpha1.HealthCheck, jobs []*batchv1.Job, scheduled time.Time) ([]*batchv1.Job, error) {
+	name := fmt.Sprintf("%s-%d", objectName(hc), scheduled.Unix())
+	var active []*batchv1.Job
+	for _, job := range jobs {
+		if _, finished := getJobResult(job); !finished && job.GetName() != name {
+			active = append(active, job)
+		}
+	}
+
+	switch concurrencyPolicy(hc.Spec) {
+	case batchv1beta1.ForbidConcurrent:
+		if len(active) > 0 {
+			return jobs, nil
+		}
+	case batchv1beta1.ReplaceConcurrent:
+		propagation := metav1.DeletePropagationBackground
+		for _, job := range active {
+			klog.V(4).Infof("Deleting Job '%s' to replace it with the next run of HealthCheck '%s'", job.GetName(), hc.GetName())
+			err := c.kubeclientset.BatchV1().Jobs(job.GetNamespace()).Delete(job.GetName(), &metav1.DeleteOptions{PropagationPolicy: &propagation})
+			if err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+		}
+		jobs = removeJobs(jobs, active)
+	}
+
+	job, err := c.createScheduledJob(hc, name)
+	if err != nil {
+		return nil, err
+	}
+	if job != nil {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// createScheduledJob creates the Job with the given name. It returns nil if
+// the Job already exists.
+func (c *Controller) createScheduledJob(hc *healthv1alpha1.HealthCheck, name string) (*batchv1.Job, error) {
+	if _, err := c.jobsLister.Jobs(hc.GetNamespace()).Get(name); err == nil {
+		return nil, nil
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	klog.V(4).Infof("Creating Job '%s' for HealthCheck '%s'", name, hc.GetName())
+	job, err := c.kubeclientset.BatchV1().Jobs(hc.GetNamespace()).Create(newJob(hc, name, c.config.CheckerImage))
+	if errors.IsAlreadyExists(err) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// deleteOwnedCronJob deletes the HealthCheck's CronJob, if it has one.
+func (c *Controller) deleteOwnedCronJob(hc *healthv1alpha1.HealthCheck) error {
+	cronjobName := hc.Status.CronJobName
+	if cronjobName == "" {
+		cronjobName = objectName(hc)
+	}
+	cronjob, err := c.cronjobsLister.CronJobs(hc.GetNamespace()).Get(cronjobName)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(cronjob, hc) {
+		return nil
+	}
+
+	klog.V(4).Infof("Deleting CronJob '%s' as HealthCheck '%s' is scheduled by the controller", cronjob.GetName(), hc.GetName())
+	err = c.kubeclientset.BatchV1beta1().CronJobs(hc.GetNamespace()).Delete(cronjob.GetName(), &metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteOldJobs deletes finished Jobs beyond the HealthCheck's history limits,
+// oldest first.
+func (c *Controller) deleteOldJobs(hc *healthv1alpha1.HealthCheck, jobs []*batchv1.Job) error {
+	var succeeded, failed []runResult
+	for _, job := range jobs {
+		result, ok := getJobResult(job)
+		if !ok {
+			continue
+		}
+		if result.passed {
+			succeeded = append(succeeded, result)
+		} else {
+			failed = append(failed, result)
+		}
+	}
+
+	namespace := ""
+	if len(jobs) > 0 {
+		namespace = jobs[0].GetNamespace()
+	}
+	propagation := metav1.DeletePropagationBackground
+	limits := []int{int(successfulJobsHistoryLimit(hc.Spec, c.config)), int(failedJobsHistoryLimit(hc.Spec, c.config))}
+	for i, results := range [][]runResult{succeeded, failed} {
+		limit := limits[i]
+		if len(results) <= limit {
+			continue
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].finished.Before(results[j].finished)
+		})
+		for _, result := range results[:len(results)-limit] {
+			err := c.kubeclientset.BatchV1().Jobs(namespace).Delete(result.name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newJob returns a Job running the HealthCheck once, owned by the HealthCheck.
+func newJob(hc *healthv1alpha1.HealthCheck, name, checkerImage string) *batchv1.Job {
+	template := newJobTemplate(hc, checkerImage)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: hc.GetNamespace(),
+			Labels:    template.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*controllerRef(hc),
+			},
+		},
+		Spec: template.Spec,
+	}
+}
+
+// removeJobs returns the Jobs that aren't in removed.
+func removeJobs(jobs, removed []*batchv1.Job) []*batchv1.Job {
+	kept := make([]*batchv1.Job, 0, len(jobs))
+	for _, job := range jobs {
+		found := false
+		for _, r := range removed {
+			if r == job {
+				found = true
+				break
+			}
+		}
+		if !found {
+			kept = append(kept, job)
+		}
+	}
+	return kept
+}
+
+// scheduledTime returns the most recent time at or before now that a run is
+// scheduled for. Runs are aligned to multiples of the interval, shifted by
+// offset.
+func scheduledTime(now time.Time, interval, offset time.Duration) time.Time {
+	return now.Add(-offset).Truncate(interval).Add(offset)
+}
+
+// jitter returns a stable offset for the HealthCheck's schedule, so that
+// HealthChecks sharing a frequency don't all run at the same instant. Every
+// controller computes the same offset for a HealthCheck.
+func jitter(hc *healthv1alpha1.HealthCheck, interval time.Duration) time.Duration {
+	max := int64(interval / jitterDivisor / time.Second)
+	if max <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(hc.GetUID()))
+	return time.Duration(h.Sum64()%uint64(max)) * time.Second
+}
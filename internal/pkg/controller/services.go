@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+const (
+	// AnnotationHealthCheck enables generated HealthChecks for a Service when
+	// set to "true".
+	AnnotationHealthCheck = "healthcheck"
+	// AnnotationCheckType overrides the type of check generated for every
+	// port of a Service. One of "http", "https" or "tcp".
+	AnnotationCheckType = "health.mbell.dev/check-type"
+	// AnnotationPath sets the path requested by generated HTTP checks.
+	AnnotationPath = "health.mbell.dev/path"
+	// AnnotationFrequency sets the frequency of generated checks.
+	AnnotationFrequency = "health.mbell.dev/frequency"
+
+	// serviceLabel is applied to HealthChecks generated for a Service. Its
+	// value is the name of the Service.
+	serviceLabel = "health.mbell.dev/service"
+
+	checkTypeHTTP  = "http"
+	checkTypeHTTPS = "https"
+	checkTypeTCP   = "tcp"
+
+	defaultServiceCheckFrequency = "1m"
+	defaultServiceCheckPath      = "/"
+)
+
+// defaultPortCheckTypes are the checks generated for well known ports when a
+// Service doesn't set a check type. 443 only gets a TCP check, since few
+// certificates are valid for the Service's cluster DNS name.
+var defaultPortCheckTypes = map[int32]string{
+	80:   checkTypeHTTP,
+	443:  checkTypeTCP,
+	8000: checkTypeHTTP,
+	8080: checkTypeHTTP,
+	5433: checkTypeTCP,
+}
+
+func (c *Controller) enqueueService(obj interface{}) {
+	var (
+		key string
+		err error
+	)
+
+	if key, err = cache.DeletionHandlingMetaNamespaceKeyFunc(obj); err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.serviceWorkqueue.Add(key)
+}
+
+// handleServiceHealthCheck enqueues the Service that owns a HealthCheck, so
+// that changes to generated HealthChecks are reconciled.
+func (c *Controller) handleServiceHealthCheck(obj interface{}) {
+	object, ok := obj.(metav1.Object)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if object, ok = tombstone.Obj.(metav1.Object); !ok {
+			return
+		}
+	}
+	if ownerRef := metav1.GetControllerOf(object); ownerRef != nil && ownerRef.Kind == "Service" {
+		c.serviceWorkqueue.Add(object.GetNamespace() + "/" + ownerRef.Name)
+	}
+}
+
+// serviceHealthCheckChanged returns true if an update to a HealthCheck could
+// make it differ from what its Service wants: a change to its spec or labels,
+// or its deletion.
+func serviceHealthCheckChanged(old, new *healthv1alpha1.HealthCheck) bool {
+	return old.Generation != new.Generation ||
+		!reflect.DeepEqual(old.Labels, new.Labels) ||
+		!old.DeletionTimestamp.Equal(new.DeletionTimestamp)
+}
+
+// syncService makes sure the HealthChecks generated for a Service match its
+// ports and annotations.
+func (c *Controller) syncService(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key '%s'", key))
+		return nil
+	}
+
+	service, err := c.servicesLister.Services(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// Generated HealthChecks are garbage collected with their Service.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	desired, err := newServiceHealthChecks(service, c.config)
+	if err != nil {
+		c.recorder.Event(service, corev1.EventTypeWarning, ErrInvalidSpec, err.Error())
+		// Keep the existing HealthChecks until the annotations are fixed.
+		return nil
+	}
+
+	lister := c.healthchecksLister.HealthChecks(namespace)
+	existing, err := lister.List(labels.SelectorFromSet(labels.Set{serviceLabel: service.GetName()}))
+	if err != nil {
+		return err
+	}
+	owned := make(map[string]*healthv1alpha1.HealthCheck)
+	for _, hc := range existing {
+		if metav1.IsControlledBy(hc, service) {
+			owned[hc.GetName()] = hc
+		}
+	}
+
+	healthchecks := c.healthclientset.HealthV1alpha1().HealthChecks(namespace)
+	for _, hc := range desired {
+		current, ok := owned[hc.GetName()]
+		delete(owned, hc.GetName())
+		if !ok {
+			// A generated HealthCheck whose label was removed is only
+			// found by name, and has its label put back.
+			if found, err := lister.Get(hc.GetName()); err == nil && metav1.IsControlledBy(found, service) {
+				current, ok = found, true
+			}
+		}
+		if !ok {
+			klog.V(4).Infof("Creating HealthCheck '%s' for Service '%s'", hc.GetName(), service.GetName())
+			_, err := healthchecks.Create(hc)
+			if errors.IsAlreadyExists(err) {
+				msg := fmt.Sprintf(MessageResourceExists, hc.GetName())
+				c.recorder.Event(service, corev1.EventTypeWarning, ErrResourceExists, msg)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if reflect.DeepEqual(current.Spec, hc.Spec) && reflect.DeepEqual(current.Labels, hc.Labels) {
+			continue
+		}
+		klog.V(4).Infof("Updating HealthCheck '%s' to reflect changes from Service '%s'", hc.GetName(), service.GetName())
+		update := current.DeepCopy()
+		update.Spec = hc.Spec
+		update.Labels = hc.Labels
+		if _, err := healthchecks.Update(update); err != nil {
+			return err
+		}
+	}
+
+	// Anything left is no longer wanted.
+	for _, hc := range owned {
+		klog.V(4).Infof("Deleting HealthCheck '%s' no longer wanted by Service '%s'", hc.GetName(), service.GetName())
+		if err := healthchecks.Delete(hc.GetName(), &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// newServiceHealthChecks returns the HealthChecks a Service's annotations ask
+// for, one per checked port.
+func newServiceHealthChecks(service *corev1.Service, cfg *config.Config) ([]*healthv1alpha1.HealthCheck, error) {
+	annotations := service.GetAnnotations()
+	if annotations[AnnotationHealthCheck] != "true" {
+		return nil, nil
+	}
+
+	checkType := strings.ToLower(annotations[AnnotationCheckType])
+	switch checkType {
+	case "", checkTypeHTTP, checkTypeHTTPS, checkTypeTCP:
+	default:
+		return nil, fmt.Errorf("unknown check type %q in annotation %s", checkType, AnnotationCheckType)
+	}
+	path := annotations[AnnotationPath]
+	if path == "" {
+		path = defaultServiceCheckPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	checkFrequency := annotations[AnnotationFrequency]
+	if checkFrequency == "" {
+		checkFrequency = defaultServiceCheckFrequency
+	}
+	freq, err := frequency.ParseFrequency(checkFrequency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid frequency in annotation %s: %s", AnnotationFrequency, err.Error())
+	}
+	if freq.ToDuration() < MinInterval {
+		return nil, fmt.Errorf("frequency in annotation %s must be at least %s", AnnotationFrequency, MinInterval)
+	}
+
+	host := fmt.Sprintf("%s.%s.svc", service.GetName(), service.GetNamespace())
+	var healthchecks []*healthv1alpha1.HealthCheck
+	for _, port := range service.Spec.Ports {
+		if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+			continue
+		}
+		portCheckType := checkType
+		if portCheckType == "" {
+			portCheckType = defaultPortCheckTypes[port.Port]
+		}
+
+		spec := healthv1alpha1.HealthCheckSpec{Frequency: checkFrequency}
+		switch portCheckType {
+		case checkTypeHTTP, checkTypeHTTPS:
+			spec.HTTP = &healthv1alpha1.HTTPProbe{
+				URL: fmt.Sprintf("%s://%s:%d%s", portCheckType, host, port.Port, path),
+			}
+		case checkTypeTCP:
+			spec.TCP = &healthv1alpha1.TCPProbe{Host: host, Port: port.Port}
+		default:
+			continue
+		}
+		// Fill in the defaults the admission webhook would, so the stored
+		// HealthCheck doesn't look like it needs updating.
+		SetDefaults(&spec, cfg)
+
+		// Generated HealthChecks have their Service's labels, so that they
+		// are watched by controllers with a label selector that watch the
+		// Service.
+		hcLabels := map[string]string{serviceLabel: service.GetName()}
+		for k, v := range service.GetLabels() {
+			if k != serviceLabel {
+				hcLabels[k] = v
+			}
+		}
+		healthchecks = append(healthchecks, &healthv1alpha1.HealthCheck{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", service.GetName(), port.Port),
+				Namespace: service.GetNamespace(),
+				Labels:    hcLabels,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(service, corev1.SchemeGroupVersion.WithKind("Service")),
+				},
+			},
+			Spec: spec,
+		})
+	}
+	return healthchecks, nil
+}
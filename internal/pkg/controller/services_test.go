@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newService(name string, annotations map[string]string, ports ...int32) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   metav1.NamespaceDefault,
+			Annotations: annotations,
+		},
+	}
+	for _, port := range ports {
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Port: port, Protocol: corev1.ProtocolTCP})
+	}
+	return svc
+}
+
+func newServiceHealthCheck(svc *corev1.Service, name string, spec healthv1alpha1.HealthCheckSpec) *healthv1alpha1.HealthCheck {
+	SetDefaults(&spec, testConfig)
+	return &healthv1alpha1.HealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: svc.Namespace,
+			Labels:    map[string]string{serviceLabel: svc.Name},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(svc, corev1.SchemeGroupVersion.WithKind("Service")),
+			},
+		},
+		Spec: spec,
+	}
+}
+
+func TestNewServiceHealthChecks(t *testing.T) {
+	svc := newService("web", map[string]string{AnnotationHealthCheck: "true"}, 80, 443, 5433, 9090)
+	svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Port: 8080, Protocol: corev1.ProtocolUDP})
+
+	healthchecks, err := newServiceHealthChecks(svc, testConfig)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := map[string]healthv1alpha1.HealthCheckSpec{
+		"web-80": {
+			Frequency: "1m",
+			HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:80/"},
+		},
+		"web-443": {
+			Frequency: "1m",
+			TCP:       &healthv1alpha1.TCPProbe{Host: "web.default.svc", Port: 443},
+		},
+		"web-5433": {
+			Frequency: "1m",
+			TCP:       &healthv1alpha1.TCPProbe{Host: "web.default.svc", Port: 5433},
+		},
+	}
+	if len(healthchecks) != len(expected) {
+		t.Fatalf("expected %d HealthChecks but got %d", len(expected), len(healthchecks))
+	}
+	for _, hc := range healthchecks {
+		checkSpec(t, hc, expected[hc.Name])
+	}
+}
+
+func TestNewServiceHealthChecksLabels(t *testing.T) {
+	svc := newService("web", map[string]string{AnnotationHealthCheck: "true"}, 80)
+	svc.Labels = map[string]string{"team": "checkout", serviceLabel: "other"}
+
+	healthchecks, err := newServiceHealthChecks(svc, testConfig)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := map[string]string{"team": "checkout", serviceLabel: "web"}
+	if len(healthchecks) != 1 || !reflect.DeepEqual(healthchecks[0].Labels, expected) {
+		t.Errorf("expected a HealthCheck with labels %v, got %+v", expected, healthchecks)
+	}
+}
+
+func TestNewServiceHealthChecksAnnotations(t *testing.T) {
+	svc := newService("web", map[string]string{
+		AnnotationHealthCheck: "true",
+		AnnotationCheckType:   "http",
+		AnnotationPath:        "healthz",
+		AnnotationFrequency:   "30s",
+	}, 9090)
+
+	healthchecks, err := newServiceHealthChecks(svc, testConfig)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(healthchecks) != 1 {
+		t.Fatalf("expected 1 HealthCheck but got %d", len(healthchecks))
+	}
+	checkSpec(t, healthchecks[0], healthv1alpha1.HealthCheckSpec{
+		Frequency: "30s",
+		HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:9090/healthz"},
+	})
+
+	svc.Annotations[AnnotationCheckType] = "carrier-pigeon"
+	if _, err := newServiceHealthChecks(svc, testConfig); err == nil {
+		t.Errorf("expected error for unknown check type")
+	}
+
+	svc.Annotations[AnnotationCheckType] = "http"
+	for _, freq := range []string{"every minute", "0s"} {
+		svc.Annotations[AnnotationFrequency] = freq
+		if _, err := newServiceHealthChecks(svc, testConfig); err == nil {
+			t.Errorf("expected error for frequency %q", freq)
+		}
+	}
+}
+
+func checkSpec(t *testing.T, hc *healthv1alpha1.HealthCheck, expected healthv1alpha1.HealthCheckSpec) {
+	SetDefaults(&expected, testConfig)
+	if err := ValidateSpec(hc.Spec); err != nil {
+		t.Errorf("HealthCheck %s has invalid spec: %v", hc.Name, err)
+	}
+	if hc.Spec.Frequency != expected.Frequency {
+		t.Errorf("HealthCheck %s: expected frequency %q but got %q", hc.Name, expected.Frequency, hc.Spec.Frequency)
+	}
+	if !reflect.DeepEqual(hc.Spec.HTTP, expected.HTTP) {
+		t.Errorf("HealthCheck %s: expected http probe %+v but got %+v", hc.Name, expected.HTTP, hc.Spec.HTTP)
+	}
+	if !reflect.DeepEqual(hc.Spec.TCP, expected.TCP) {
+		t.Errorf("HealthCheck %s: expected tcp probe %+v but got %+v", hc.Name, expected.TCP, hc.Spec.TCP)
+	}
+}
+
+func TestCreatesServiceHealthChecks(t *testing.T) {
+	tc := newTestCase(t)
+	svc := newService("web", map[string]string{AnnotationHealthCheck: "true"}, 80)
+	tc.svcLister = append(tc.svcLister, svc)
+	tc.kubeObjects = append(tc.kubeObjects, svc)
+
+	tc.expectCreateHealthCheckAction(newServiceHealthCheck(svc, "web-80", healthv1alpha1.HealthCheckSpec{
+		Frequency: "1m",
+		HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:80/"},
+	}))
+	tc.runService("default/web")
+}
+
+func TestUpdatesAndDeletesServiceHealthChecks(t *testing.T) {
+	tc := newTestCase(t)
+	svc := newService("web", map[string]string{AnnotationHealthCheck: "true", AnnotationFrequency: "5m"}, 80)
+	tc.svcLister = append(tc.svcLister, svc)
+	tc.kubeObjects = append(tc.kubeObjects, svc)
+
+	stale := newServiceHealthCheck(svc, "web-80", healthv1alpha1.HealthCheckSpec{
+		Frequency: "1m",
+		HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:80/"},
+	})
+	removed := newServiceHealthCheck(svc, "web-5433", healthv1alpha1.HealthCheckSpec{
+		Frequency: "1m",
+		TCP:       &healthv1alpha1.TCPProbe{Host: "web.default.svc", Port: 5433},
+	})
+	unowned := newHealthCheck("other", "nginx", "", "* * * * *", nil)
+	for _, hc := range []*healthv1alpha1.HealthCheck{stale, removed, unowned} {
+		tc.hcLister = append(tc.hcLister, hc)
+		tc.objects = append(tc.objects, hc)
+	}
+
+	updated := stale.DeepCopy()
+	updated.Spec.Frequency = "5m"
+	tc.expectUpdateHealthCheckAction(updated)
+	tc.expectDeleteHealthCheckAction(removed)
+	tc.runService("default/web")
+}
+
+func TestRestoresServiceHealthCheckLabel(t *testing.T) {
+	tc := newTestCase(t)
+	svc := newService("web", map[string]string{AnnotationHealthCheck: "true"}, 80)
+	tc.svcLister = append(tc.svcLister, svc)
+	tc.kubeObjects = append(tc.kubeObjects, svc)
+
+	hc := newServiceHealthCheck(svc, "web-80", healthv1alpha1.HealthCheckSpec{
+		Frequency: "1m",
+		HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:80/"},
+	})
+	unlabelled := hc.DeepCopy()
+	unlabelled.Labels = nil
+	tc.hcLister = append(tc.hcLister, unlabelled)
+	tc.objects = append(tc.objects, unlabelled)
+
+	tc.expectUpdateHealthCheckAction(hc)
+	tc.runService("default/web")
+}
+
+func TestServiceHealthCheckChanged(t *testing.T) {
+	svc := newService("web", nil, 80)
+	hc := newServiceHealthCheck(svc, "web-80", healthv1alpha1.HealthCheckSpec{Frequency: "1m"})
+	hc.Generation = 1
+
+	statusUpdate := hc.DeepCopy()
+	statusUpdate.ResourceVersion = "2"
+	statusUpdate.Status.Healthy = true
+	specUpdate := hc.DeepCopy()
+	specUpdate.Generation = 2
+	labelUpdate := hc.DeepCopy()
+	labelUpdate.Labels = nil
+	deleted := hc.DeepCopy()
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+
+	tests := []struct {
+		name     string
+		new      *healthv1alpha1.HealthCheck
+		expected bool
+	}{
+		{name: "resync", new: hc},
+		{name: "status update", new: statusUpdate},
+		{name: "spec update", new: specUpdate, expected: true},
+		{name: "label update", new: labelUpdate, expected: true},
+		{name: "deletion", new: deleted, expected: true},
+	}
+	for _, test := range tests {
+		if changed := serviceHealthCheckChanged(hc, test.new); changed != test.expected {
+			t.Errorf("%s: expected changed to be %t", test.name, test.expected)
+		}
+	}
+}
+
+func TestDeletesHealthChecksWhenAnnotationRemoved(t *testing.T) {
+	tc := newTestCase(t)
+	svc := newService("web", nil, 80)
+	tc.svcLister = append(tc.svcLister, svc)
+	tc.kubeObjects = append(tc.kubeObjects, svc)
+
+	hc := newServiceHealthCheck(svc, "web-80", healthv1alpha1.HealthCheckSpec{
+		Frequency: "1m",
+		HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:80/"},
+	})
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	tc.expectDeleteHealthCheckAction(hc)
+	tc.runService("default/web")
+}
+
+func TestKeepsHealthChecksForInvalidFrequency(t *testing.T) {
+	tc := newTestCase(t)
+	svc := newService("web", map[string]string{AnnotationHealthCheck: "true", AnnotationFrequency: "0s"}, 80)
+	tc.svcLister = append(tc.svcLister, svc)
+	tc.kubeObjects = append(tc.kubeObjects, svc)
+
+	hc := newServiceHealthCheck(svc, "web-80", healthv1alpha1.HealthCheckSpec{
+		Frequency: "1m",
+		HTTP:      &healthv1alpha1.HTTPProbe{URL: "http://web.default.svc:80/"},
+	})
+	tc.hcLister = append(tc.hcLister, hc)
+	tc.objects = append(tc.objects, hc)
+
+	// The Service gets an Event rather than the HealthCheck being replaced
+	// with one that would be rejected.
+	tc.runService("default/web")
+}
@@ -3,10 +3,15 @@ package controller
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/checker"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/metrics"
 	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -18,7 +23,12 @@ import (
 )
 
 const (
-	defaultCronPattern = "*/1 * * * *"
+	// MinInterval is the shortest frequency a HealthCheck may run at.
+	MinInterval = time.Second
+	// checkerCommand is the path of the checker binary in the checker image.
+	checkerCommand = "/hc-checker"
+	// checkContainerName is the name of the container that runs the check.
+	checkContainerName = "healthcheck"
 )
 
 func (c *Controller) syncHandler(key string) error {
@@ -28,7 +38,7 @@ func (c *Controller) syncHandler(key string) error {
 		return nil
 	}
 
-	healthcheck, err := c.healthchecksLister.HealthChecks(namespace).Get(name)
+	healthcheck, err := c.getHealthCheck(namespace, name)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			utilruntime.HandleError(fmt.Errorf("HealthCheck '%s' in work queue no longer exists", key))
@@ -37,16 +47,48 @@ func (c *Controller) syncHandler(key string) error {
 		return err
 	}
 
+	if err := ValidateSpec(healthcheck.Spec); err != nil {
+		// The spec needs to change before this can succeed, so don't requeue.
+		msg := fmt.Sprintf(MessageInvalidSpec, err.Error())
+		c.recorder.Event(eventObject(healthcheck), corev1.EventTypeWarning, ErrInvalidSpec, msg)
+		return c.updateStatusForSyncError(healthcheck, healthv1alpha1.HealthCheckInvalidSpec, ErrInvalidSpec, msg)
+	}
+
+	if healthcheck.Spec.Pods != nil {
+		// Pods are sampled by the controller rather than by a Job.
+		return c.syncPodsHealthCheck(key, healthcheck)
+	}
+
+	schedule, err := cronSchedule(healthcheck, c.config)
+	if frequency.IsNotCronExpressible(err) {
+		// Cron can't run this HealthCheck, so schedule its Jobs ourselves.
+		return c.syncScheduledHealthCheck(key, healthcheck)
+	}
+	if err != nil {
+		// The spec needs to change before this can succeed, so don't requeue.
+		msg := fmt.Sprintf(MessageInvalidFrequency, err.Error())
+		c.recorder.Event(eventObject(healthcheck), corev1.EventTypeWarning, ErrInvalidFrequency, msg)
+		return c.updateStatusForSyncError(healthcheck, healthv1alpha1.HealthCheckInvalidSpec, ErrInvalidFrequency, msg)
+	}
+
 	cronjobName := healthcheck.Status.CronJobName
 	if cronjobName == "" {
-		cronjobName = healthcheck.GetName()
+		cronjobName = objectName(healthcheck)
+	}
+
+	// CronJobs are suspended during maintenance windows, and resumed when
+	// they end.
+	newCronjob := newCronJob(healthcheck, cronjobName, schedule, c.config)
+	now := c.clock.Now()
+	if _, inMaintenance := maintenanceUntil(healthcheck.Spec, now); inMaintenance {
+		newCronjob.Spec.Suspend = boolPtr(true)
 	}
 
 	// Get specified CronJob.
 	cronjob, err := c.cronjobsLister.CronJobs(healthcheck.GetNamespace()).Get(cronjobName)
 	// If not found, create a new one.
 	if errors.IsNotFound(err) {
-		cronjob, err = c.kubeclientset.BatchV1beta1().CronJobs(healthcheck.GetNamespace()).Create(newCronJob(healthcheck, cronjobName))
+		cronjob, err = c.kubeclientset.BatchV1beta1().CronJobs(healthcheck.GetNamespace()).Create(newCronjob)
 	}
 
 	// Throw error so the work item can be retried.
@@ -56,11 +98,13 @@ func (c *Controller) syncHandler(key string) error {
 
 	if !metav1.IsControlledBy(cronjob, healthcheck) {
 		msg := fmt.Sprintf(MessageResourceExists, cronjob.GetName())
-		c.recorder.Event(healthcheck, corev1.EventTypeWarning, ErrResourceExists, msg)
+		c.recorder.Event(eventObject(healthcheck), corev1.EventTypeWarning, ErrResourceExists, msg)
+		if err := c.updateStatusForSyncError(healthcheck, healthv1alpha1.HealthCheckResourceConflict, ErrResourceExists, msg); err != nil {
+			return err
+		}
 		return fmt.Errorf(msg)
 	}
 
-	newCronjob := newCronJob(healthcheck, cronjobName)
 	if !reflect.DeepEqual(cronjob.Spec, newCronjob.Spec) {
 		klog.V(4).Infof("Updating CronJob '%s' to reflect changes from HealthCheck '%s'", cronjob.GetName(), healthcheck.GetName())
 		cronjob, err = c.kubeclientset.BatchV1beta1().CronJobs(healthcheck.GetNamespace()).Update(newCronjob)
@@ -71,71 +115,288 @@ func (c *Controller) syncHandler(key string) error {
 		return err
 	}
 
-	err = c.updateHealthCheckStatus(healthcheck, cronjob)
+	jobs, err := c.jobsForHealthCheck(healthcheck, cronjob)
 	if err != nil {
 		return err
 	}
 
-	c.recorder.Event(healthcheck, corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
+	if runNowRequested(healthcheck) {
+		var job *batchv1.Job
+		healthcheck, job, err = c.triggerRun(healthcheck, newManualJob(cronjob, manualJobName(healthcheck, now)))
+		if err != nil {
+			return err
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	results := newJobResults(healthcheck.Status, jobs)
+	err = c.updateHealthCheckStatus(healthcheck, cronjob.GetName(), results, ReasonCronJobScheduled, fmt.Sprintf(MessageCronJobScheduled, cronjob.GetName()))
+	if err != nil {
+		return err
+	}
+
+	// Resync when a maintenance window starts or ends, to suspend or resume
+	// the CronJob.
+	if next, ok := nextMaintenanceChange(healthcheck.Spec, now); ok {
+		c.workqueue.AddAfter(key, next.Sub(now))
+	}
+
+	c.recorder.Event(eventObject(healthcheck), corev1.EventTypeNormal, SuccessSynced, MessageResourceSynced)
 	return nil
 }
 
-func (c *Controller) updateHealthCheckStatus(hc *healthv1alpha1.HealthCheck, cronjob *batchv1beta1.CronJob) error {
+// updateHealthCheckStatus records new results and refreshes the conditions of
+// a HealthCheck that has been scheduled.
+func (c *Controller) updateHealthCheckStatus(hc *healthv1alpha1.HealthCheck, cronjobName string, results []runResult, scheduledReason, scheduledMessage string) error {
 	healthcheckCopy := hc.DeepCopy()
-	healthcheckCopy.Status.CronJobName = cronjob.GetName()
-	_, err := c.healthclientset.HealthV1alpha1().HealthChecks(hc.GetNamespace()).Update(healthcheckCopy)
-	return err
+	healthcheckCopy.Status.ObservedGeneration = hc.GetGeneration()
+	healthcheckCopy.Status.CronJobName = cronjobName
+	wasHealthy := hc.Status.Healthy
+	results = excludeMaintenance(hc.Spec, results)
+	c.readCheckOutput(hc, results)
+	recorded := recordResults(hc.Spec, &healthcheckCopy.Status, results)
+	c.setSyncedConditions(healthcheckCopy, scheduledReason, scheduledMessage)
+	// The status saying notifications were sent is written before they are,
+	// so that a failed write can't send them twice. Notifications that then
+	// can't be sent are taken back out of the status, so they are retried the
+	// next time the HealthCheck is synced.
+	alerts := c.planAlerts(healthcheckCopy)
+	updated, err := c.updateStatus(healthcheckCopy)
+	if err != nil {
+		return err
+	}
+	if restored, failed := c.sendAlerts(healthcheckCopy, alerts); failed {
+		updated.Status.Alerts = restored
+		if _, err := c.updateStatus(updated); err != nil {
+			return err
+		}
+	}
+
+	metrics.SetHealthCheckStatus(objectNamespace(hc), hc.GetName(), healthcheckCopy.Status.Healthy, healthcheckCopy.Status.AverageHealthiness)
+	for _, result := range results {
+		metrics.RecordRun(objectNamespace(hc), hc.GetName(), result.passed, result.duration, result.finished)
+	}
+
+	// Only report transitions once there is a result to transition from, so
+	// that a new HealthCheck passing its first run isn't reported as a
+	// recovery.
+	status := healthcheckCopy.Status
+	if recorded && status.Healthy != wasHealthy && (hc.Status.LastRunTime != nil || !status.Healthy) {
+		if status.Healthy {
+			c.recorder.Eventf(eventObject(hc), corev1.EventTypeNormal, BecameHealthy, MessageBecameHealthy, status.AverageHealthiness)
+		} else {
+			c.recorder.Eventf(eventObject(hc), corev1.EventTypeWarning, BecameUnhealthy, MessageBecameUnhealthy, status.AverageHealthiness)
+		}
+	}
+	return nil
 }
 
-func newCronJob(hc *healthv1alpha1.HealthCheck, name string) *batchv1beta1.CronJob {
-	labels := map[string]string{
-		"controller": hc.GetName(),
+// ValidateSpec checks that the HealthCheck spec describes exactly one check,
+// and that the rest of it can be run.
+func ValidateSpec(spec healthv1alpha1.HealthCheckSpec) error {
+	// Frequencies that don't parse are reported when the HealthCheck is
+	// scheduled.
+	if freq, err := frequency.ParseFrequency(spec.Frequency); len(spec.Frequency) > 0 && err == nil && freq.ToDuration() < MinInterval {
+		return fmt.Errorf("frequency must be at least %s", MinInterval)
+	}
+	checks := 0
+	if len(spec.Image) > 0 {
+		checks++
+	}
+	probes, err := validateProbes(spec.HTTP, spec.TCP, spec.DNS)
+	if err != nil {
+		return err
+	}
+	checks += probes
+	if len(spec.Steps) > 0 {
+		if err := checker.ValidateSteps(spec.Steps); err != nil {
+			return err
+		}
+		checks++
+	}
+	if len(spec.Containers) > 0 {
+		if err := validateContainers(spec); err != nil {
+			return err
+		}
+		checks++
+	}
+	if spec.Pods != nil {
+		selector, err := metav1.LabelSelectorAsSelector(&spec.Pods.Selector)
+		if err != nil {
+			return fmt.Errorf("pods probe has an invalid selector: %s", err.Error())
+		}
+		if selector.Empty() {
+			return fmt.Errorf("pods probe must have a selector")
+		}
+		if len(spec.CronPattern) > 0 {
+			return fmt.Errorf("pods probe can't use a cronPattern, set a frequency instead")
+		}
+		checks++
+	}
+	if checks != 1 {
+		return fmt.Errorf("exactly one of image, http, tcp, dns, steps, containers or pods must be set")
 	}
+	if spec.FailureThreshold < 0 || spec.FailureThreshold > maxResults {
+		return fmt.Errorf("failureThreshold must be between 1 and %d", maxResults)
+	}
+	if spec.SuccessThreshold < 0 || spec.SuccessThreshold > maxResults {
+		return fmt.Errorf("successThreshold must be between 1 and %d", maxResults)
+	}
+	if spec.MinAverageHealthiness < 0 || spec.MinAverageHealthiness > 1 {
+		return fmt.Errorf("minAverageHealthiness must be between 0 and 1")
+	}
+	if err := validateExecution(spec); err != nil {
+		return err
+	}
+	if err := validateMaintenanceWindows(spec); err != nil {
+		return err
+	}
+	if spec.Template != nil {
+		if err := validateTemplate(spec); err != nil {
+			return err
+		}
+	}
+	if spec.Alerting != nil {
+		return validateAlerting(spec.Alerting)
+	}
+	return nil
+}
 
-	schedule := defaultCronPattern
+// cronSchedule returns the cron schedule a HealthCheck's CronJob should run
+// on. An explicit CronPattern takes precedence over Frequency, and the
+// configured default pattern is used if neither is set.
+func cronSchedule(hc *healthv1alpha1.HealthCheck, cfg *config.Config) (string, error) {
 	if len(hc.Spec.CronPattern) > 0 {
-		schedule = hc.Spec.CronPattern
+		return hc.Spec.CronPattern, nil
 	}
+	if len(hc.Spec.Frequency) > 0 {
+		freq, err := frequency.ParseFrequency(hc.Spec.Frequency)
+		if err != nil {
+			return "", err
+		}
+		return freq.ToCronExpr()
+	}
+	return cfg.DefaultCronPattern, nil
+}
 
+func newCronJob(hc *healthv1alpha1.HealthCheck, name, schedule string, cfg *config.Config) *batchv1beta1.CronJob {
 	return &batchv1beta1.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: hc.GetNamespace(),
 			OwnerReferences: []metav1.OwnerReference{
-				*metav1.NewControllerRef(hc, healthv1alpha1.SchemeGroupVersion.WithKind("HealthCheck")),
+				*controllerRef(hc),
 			},
 		},
 		Spec: batchv1beta1.CronJobSpec{
-			FailedJobsHistoryLimit:     int32Ptr(10),
-			SuccessfulJobsHistoryLimit: int32Ptr(10),
-			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
-			StartingDeadlineSeconds:    int64Ptr(10),
+			FailedJobsHistoryLimit:     int32Ptr(failedJobsHistoryLimit(hc.Spec, cfg)),
+			SuccessfulJobsHistoryLimit: int32Ptr(successfulJobsHistoryLimit(hc.Spec, cfg)),
+			ConcurrencyPolicy:          concurrencyPolicy(hc.Spec),
+			StartingDeadlineSeconds:    int64Ptr(startingDeadlineSeconds(hc.Spec)),
 			Schedule:                   schedule,
-			Suspend:                    boolPtr(false),
-			JobTemplate: batchv1beta1.JobTemplateSpec{
-				Spec: batchv1.JobSpec{
-					BackoffLimit: int32Ptr(0),
-					Template: corev1.PodTemplateSpec{
-						ObjectMeta: metav1.ObjectMeta{
-							Labels: labels,
-						},
-						Spec: corev1.PodSpec{
-							RestartPolicy: corev1.RestartPolicyNever,
-							Containers: []corev1.Container{
-								{
-									Name:  "healthcheck",
-									Image: hc.Spec.Image,
-									Args:  hc.Spec.Args,
-								},
-							},
-						},
-					},
-				},
-			},
+			Suspend:                    boolPtr(hc.Spec.Suspend),
+			JobTemplate:                newJobTemplate(hc, cfg.CheckerImage),
 		},
 	}
 }
 
+// newJobTemplate returns the template for the Jobs that run a HealthCheck,
+// whether they are created by a CronJob or by the controller itself.
+func newJobTemplate(hc *healthv1alpha1.HealthCheck, checkerImage string) batchv1beta1.JobTemplateSpec {
+	labels := map[string]string{
+		controllerLabel: objectName(hc),
+	}
+
+	template := corev1.PodTemplateSpec{}
+	if hc.Spec.Template != nil {
+		template = *hc.Spec.Template.DeepCopy()
+	}
+	podLabels := make(map[string]string, len(template.Labels)+1)
+	for k, v := range template.Labels {
+		podLabels[k] = v
+	}
+	podLabels[controllerLabel] = objectName(hc)
+	template.Labels = podLabels
+	template.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	// Each check runs in the template's container of the same name if it
+	// has one, so that it can set Command, Env, Resources and so on.
+	checks := checkContainers(hc.Spec)
+	sequential := containerPolicy(hc.Spec) == healthv1alpha1.SequentialContainers
+	var added []corev1.Container
+	for i, check := range checks {
+		// Sequential checks run as init containers, apart from the last, as a
+		// Pod needs at least one container.
+		init := sequential && i < len(checks)-1
+		list, index := findContainer(&template.Spec, check.name)
+		container := corev1.Container{Name: check.name}
+		if index >= 0 {
+			container = (*list)[index]
+		}
+		container = check.apply(container, index < 0, checkerImage)
+
+		inPlace := index >= 0 && (list == &template.Spec.InitContainers) == init
+		switch {
+		case inPlace:
+			(*list)[index] = container
+			continue
+		case index >= 0:
+			*list = append((*list)[:index], (*list)[index+1:]...)
+		}
+		if init {
+			template.Spec.InitContainers = append(template.Spec.InitContainers, container)
+		} else {
+			added = append(added, container)
+		}
+	}
+	template.Spec.Containers = append(added, template.Spec.Containers...)
+
+	return batchv1beta1.JobTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          int32Ptr(backoffLimit(hc.Spec)),
+			ActiveDeadlineSeconds: int64Ptr(activeDeadlineSeconds(hc.Spec)),
+			Template:              template,
+		},
+	}
+}
+
+// validateTemplate checks that a pod template doesn't set any of the fields
+// the controller owns.
+func validateTemplate(spec healthv1alpha1.HealthCheckSpec) error {
+	template := spec.Template
+	if spec.Pods != nil {
+		return fmt.Errorf("pods probe can't use a template, it doesn't run any Pods")
+	}
+	if _, ok := template.Labels[controllerLabel]; ok {
+		return fmt.Errorf("template can't set the %s label", controllerLabel)
+	}
+	if len(template.OwnerReferences) > 0 {
+		return fmt.Errorf("template can't set ownerReferences")
+	}
+	if template.Spec.RestartPolicy != "" && template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		return fmt.Errorf("template restartPolicy must be Never")
+	}
+	for _, check := range checkContainers(spec) {
+		list, index := findContainer(&template.Spec, check.name)
+		if index < 0 {
+			continue
+		}
+		c := (*list)[index]
+		if len(c.Image) > 0 {
+			return fmt.Errorf("template %s container can't set an image, set image instead", check.name)
+		}
+		if check.probe != nil && (len(c.Command) > 0 || len(c.Args) > 0) {
+			return fmt.Errorf("template %s container can't set command or args for a built-in probe", check.name)
+		}
+	}
+	return nil
+}
+
 func boolPtr(b bool) *bool    { return &b }
 func int32Ptr(i int32) *int32 { return &i }
 func int64Ptr(i int64) *int64 { return &i }
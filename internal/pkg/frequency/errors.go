@@ -19,3 +19,23 @@ type errParsingToken string
 func (expr errParsingToken) Error() string {
 	return fmt.Sprintf("Couldn't parse frequency token %s", string(expr))
 }
+
+type errNotCronExpressible string
+
+func (expr errNotCronExpressible) Error() string {
+	return fmt.Sprintf("Frequency expression %s can't be represented as a cron schedule", string(expr))
+}
+
+// IsNotCronExpressible returns true if the error indicates that a valid
+// frequency has no equivalent cron schedule.
+func IsNotCronExpressible(err error) bool {
+	_, ok := err.(errNotCronExpressible)
+	return ok
+}
+
+// IsInvalidExpr returns true if the error indicates that an expression isn't
+// made up of amounts and units.
+func IsInvalidExpr(err error) bool {
+	_, ok := err.(errInvalidExpr)
+	return ok
+}
@@ -1,6 +1,7 @@
 package frequency
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,7 +21,7 @@ const (
 var (
 	frequencyToken         = `(\d+(\.\d+)?[smhdw])`
 	frequencyTokenPattern  = regexp.MustCompile(frequencyToken)
-	frequencyStringPattern = regexp.MustCompile(frequencyToken + "+$")
+	frequencyStringPattern = regexp.MustCompile("^" + frequencyToken + "+$")
 )
 
 type Frequency struct {
@@ -36,19 +37,65 @@ func EmptyFrequency() Frequency {
 }
 
 // ToCronExpr returns a cron-formatted string that represents the given
-// frequency.
-func (f Frequency) ToCronExpr() string {
-	return ""
+// frequency. Cron schedules can only express intervals of whole minutes that
+// divide an hour evenly, whole hours that divide a day evenly, a day or a
+// week; any other frequency returns an error satisfying IsNotCronExpressible.
+func (f Frequency) ToCronExpr() (string, error) {
+	d := f.ToDuration()
+	switch {
+	case d <= 0 || d%time.Minute != 0:
+		return "", errNotCronExpressible(f.String())
+	case d < time.Hour:
+		minutes := int(d / time.Minute)
+		if 60%minutes != 0 {
+			return "", errNotCronExpressible(f.String())
+		}
+		return fmt.Sprintf("*/%d * * * *", minutes), nil
+	case d%time.Hour != 0:
+		return "", errNotCronExpressible(f.String())
+	case d < dayUnit:
+		hours := int(d / time.Hour)
+		if 24%hours != 0 {
+			return "", errNotCronExpressible(f.String())
+		}
+		return fmt.Sprintf("0 */%d * * *", hours), nil
+	case d == dayUnit:
+		return "0 0 * * *", nil
+	case d == weekUnit:
+		return "0 0 * * 0", nil
+	}
+	return "", errNotCronExpressible(f.String())
 }
 
 // ToDuration returns the length of time the frequency represents.
 func (f Frequency) ToDuration() (total time.Duration) {
 	for _, cmpt := range f.components {
-		total += cmpt.Unit * time.Duration(cmpt.Amount)
+		total += time.Duration(float64(cmpt.Unit) * float64(cmpt.Amount))
 	}
 	return
 }
 
+// String returns the frequency as a normalised frequency expression.
+func (f Frequency) String() string {
+	var b strings.Builder
+	for _, cmpt := range f.components {
+		b.WriteString(strconv.FormatFloat(float64(cmpt.Amount), 'f', -1, 32))
+		switch cmpt.Unit {
+		case weekUnit:
+			b.WriteString(week)
+		case dayUnit:
+			b.WriteString(day)
+		case time.Hour:
+			b.WriteString(hour)
+		case time.Minute:
+			b.WriteString(minute)
+		case time.Second:
+			b.WriteString(second)
+		}
+	}
+	return b.String()
+}
+
 // ParseFrequency will take a frequency expression string and parse it to a
 // frequency object.
 func ParseFrequency(expr string) (Frequency, error) {
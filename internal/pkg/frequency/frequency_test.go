@@ -49,6 +49,9 @@ func (tc testCase) test() {
 	if diff > 0 {
 		tc.t.Errorf("%d expected additional components: %+v", diff, tc.expectedFreq.components[len(freq.components):])
 	}
+	if duration := freq.ToDuration(); duration != tc.expectedDuration {
+		tc.t.Errorf("wrong duration, expected %s but got %s", tc.expectedDuration, duration)
+	}
 }
 
 var tt = []testCase{
@@ -72,6 +75,12 @@ var tt = []testCase{
 		expectedErr:  errInvalidExpr("6hours"),
 		expectedFreq: nil,
 	},
+	{
+		name:         "leading_garbage_incorrect_format",
+		input:        "every 6h",
+		expectedErr:  errInvalidExpr("every 6h"),
+		expectedFreq: nil,
+	},
 	{
 		name:             "sixhourstwominutes_correct_format",
 		input:            "6h2m",
@@ -111,6 +120,48 @@ func TestFrequency(t *testing.T) {
 	}
 }
 
+var cronTests = []struct {
+	input        string
+	expectedCron string
+	expectedErr  error
+}{
+	{input: "1m", expectedCron: "*/1 * * * *"},
+	{input: "2m", expectedCron: "*/2 * * * *"},
+	{input: "15m", expectedCron: "*/15 * * * *"},
+	{input: "120s", expectedCron: "*/2 * * * *"},
+	{input: "1h", expectedCron: "0 */1 * * *"},
+	{input: "6h", expectedCron: "0 */6 * * *"},
+	{input: "1d", expectedCron: "0 0 * * *"},
+	{input: "1w", expectedCron: "0 0 * * 0"},
+	{input: "30s", expectedErr: errNotCronExpressible("30s")},
+	{input: "90s", expectedErr: errNotCronExpressible("90s")},
+	{input: "7m", expectedErr: errNotCronExpressible("7m")},
+	{input: "1h30m", expectedErr: errNotCronExpressible("1h30m")},
+	{input: "5h", expectedErr: errNotCronExpressible("5h")},
+	{input: "1d12h", expectedErr: errNotCronExpressible("1d12h")},
+	{input: "2d", expectedErr: errNotCronExpressible("2d")},
+}
+
+func TestToCronExpr(t *testing.T) {
+	for _, tc := range cronTests {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+			freq, err := ParseFrequency(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			cron, err := freq.ToCronExpr()
+			if err != tc.expectedErr {
+				t.Fatalf("expected error %v, actual error %v", tc.expectedErr, err)
+			}
+			if cron != tc.expectedCron {
+				t.Errorf("expected cron expression %q but got %q", tc.expectedCron, cron)
+			}
+		})
+	}
+}
+
 func checkComponent(t *testing.T, expected, actual frequencyComponent) error {
 	if expected.Unit != actual.Unit {
 		return fmt.Errorf("wrong unit, expected %s but got %s", expected.Unit, actual.Unit)
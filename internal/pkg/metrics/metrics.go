@@ -0,0 +1,122 @@
+// Package metrics defines the Prometheus metrics exported by the controller,
+// covering both HealthCheck results and the controller's own health.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "healthcheck"
+
+var (
+	healthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "healthy",
+		Help:      "Whether the HealthCheck is currently healthy (1) or not (0).",
+	}, []string{"namespace", "name"})
+	averageHealthiness = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "average_healthiness",
+		Help:      "Proportion of the HealthCheck's last 10 runs that passed.",
+	}, []string{"namespace", "name"})
+	lastRunDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_run_duration_seconds",
+		Help:      "How long the HealthCheck's most recently recorded run took.",
+	}, []string{"namespace", "name"})
+	lastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time at which the HealthCheck's most recent passing run finished.",
+	}, []string{"namespace", "name"})
+	runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_total",
+		Help:      "Number of HealthCheck runs recorded, by outcome.",
+	}, []string{"namespace", "name", "outcome"})
+
+	syncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "sync_errors_total",
+		Help:      "Number of failed syncs, by queue and key.",
+	}, []string{"queue", "key"})
+	leader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "leader",
+		Help:      "Whether this replica is the elected leader (1) or not (0).",
+	})
+	informerSynced = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "controller",
+		Name:      "informer_synced",
+		Help:      "Whether the informer's cache has synced (1) or not (0).",
+	}, []string{"informer"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		healthy,
+		averageHealthiness,
+		lastRunDuration,
+		lastSuccess,
+		runs,
+		syncErrors,
+		leader,
+		informerSynced,
+	)
+}
+
+// SetHealthCheckStatus records a HealthCheck's current health.
+func SetHealthCheckStatus(namespace, name string, isHealthy bool, average float32) {
+	healthy.WithLabelValues(namespace, name).Set(boolToFloat(isHealthy))
+	averageHealthiness.WithLabelValues(namespace, name).Set(float64(average))
+}
+
+// RecordRun records the outcome of a single HealthCheck run.
+func RecordRun(namespace, name string, passed bool, duration time.Duration, finished time.Time) {
+	outcome := "failed"
+	if passed {
+		outcome = "passed"
+		lastSuccess.WithLabelValues(namespace, name).Set(float64(finished.Unix()))
+	}
+	runs.WithLabelValues(namespace, name, outcome).Inc()
+	lastRunDuration.WithLabelValues(namespace, name).Set(duration.Seconds())
+}
+
+// DeleteHealthCheck removes the metrics of a deleted HealthCheck.
+func DeleteHealthCheck(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	healthy.Delete(labels)
+	averageHealthiness.Delete(labels)
+	lastRunDuration.Delete(labels)
+	lastSuccess.Delete(labels)
+	for _, outcome := range []string{"passed", "failed"} {
+		runs.DeleteLabelValues(namespace, name, outcome)
+	}
+}
+
+// RecordSyncError counts a failed sync of the key from the named queue.
+func RecordSyncError(queue, key string) {
+	syncErrors.WithLabelValues(queue, key).Inc()
+}
+
+// SetLeader records whether this replica is the elected leader.
+func SetLeader(isLeader bool) {
+	leader.Set(boolToFloat(isLeader))
+}
+
+// SetInformerSynced records whether the named informer's cache has synced.
+func SetInformerSynced(informer string, synced bool) {
+	informerSynced.WithLabelValues(informer).Set(boolToFloat(synced))
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordRun(t *testing.T) {
+	finished := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	RecordRun("default", "foo", true, 2*time.Second, finished)
+	RecordRun("default", "foo", false, 3*time.Second, finished.Add(time.Minute))
+
+	if passed := testutil.ToFloat64(runs.WithLabelValues("default", "foo", "passed")); passed != 1 {
+		t.Errorf("expected 1 passed run but got %v", passed)
+	}
+	if failed := testutil.ToFloat64(runs.WithLabelValues("default", "foo", "failed")); failed != 1 {
+		t.Errorf("expected 1 failed run but got %v", failed)
+	}
+	if duration := testutil.ToFloat64(lastRunDuration.WithLabelValues("default", "foo")); duration != 3 {
+		t.Errorf("expected last run duration 3 but got %v", duration)
+	}
+	if success := testutil.ToFloat64(lastSuccess.WithLabelValues("default", "foo")); success != float64(finished.Unix()) {
+		t.Errorf("expected last success %d but got %v", finished.Unix(), success)
+	}
+}
+
+func TestDeleteHealthCheck(t *testing.T) {
+	SetHealthCheckStatus("default", "bar", true, 0.5)
+	RecordRun("default", "bar", true, time.Second, time.Now())
+	DeleteHealthCheck("default", "bar")
+
+	if healthy.Delete(prometheus.Labels{"namespace": "default", "name": "bar"}) {
+		t.Errorf("expected healthy series to have been deleted")
+	}
+	if runs.DeleteLabelValues("default", "bar", "passed") {
+		t.Errorf("expected runs series to have been deleted")
+	}
+}
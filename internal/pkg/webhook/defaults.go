@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/controller"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+)
+
+// patchOperation is a JSON patch operation.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// defaultsPatch returns a JSON patch filling in the defaults of the
+// HealthCheck's spec, or nil if nothing needs to change. hasSpec is false if
+// the object being admitted has no spec at all.
+func defaultsPatch(hc *healthv1alpha1.HealthCheck, hasSpec bool, cfg *config.Config) ([]byte, error) {
+	defaulted := hc.Spec.DeepCopy()
+	controller.SetDefaults(defaulted, cfg)
+	// The schedule isn't defaulted, so that specs setting neither frequency
+	// nor cronPattern are rejected by the validating webhook.
+	defaulted.Frequency, defaulted.CronPattern = hc.Spec.Frequency, hc.Spec.CronPattern
+
+	original, err := toMap(hc.Spec)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := toMap(defaulted)
+	if err != nil {
+		return nil, err
+	}
+	ops := []patchOperation{{Op: "add", Path: "/spec", Value: updated}}
+	if hasSpec {
+		ops = diff("/spec", original, updated)
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(ops)
+}
+
+// toMap returns the JSON representation of v as a map.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+// diff returns the operations that turn original into updated. Fields that
+// are only removed by defaulting are left alone, since defaults only add.
+func diff(path string, original, updated map[string]interface{}) []patchOperation {
+	keys := make([]string, 0, len(updated))
+	for key := range updated {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var ops []patchOperation
+	for _, key := range keys {
+		keyPath := path + "/" + pointerEscaper.Replace(key)
+		value := updated[key]
+		old, ok := original[key]
+		if !ok {
+			ops = append(ops, patchOperation{Op: "add", Path: keyPath, Value: value})
+			continue
+		}
+		oldMap, oldIsMap := old.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		switch {
+		case oldIsMap && valueIsMap:
+			ops = append(ops, diff(keyPath, oldMap, valueMap)...)
+		case !reflect.DeepEqual(old, value):
+			ops = append(ops, patchOperation{Op: "replace", Path: keyPath, Value: value})
+		}
+	}
+	return ops
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/controller"
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/frequency"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// maxImageNameLength is the longest repository name a registry accepts.
+const maxImageNameLength = 255
+
+var (
+	// cronJobParser parses schedules as the CronJob controller does, as five
+	// fields or a descriptor.
+	cronJobParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	// cronJobDescriptors are the descriptors a CronJob's schedule can be.
+	cronJobDescriptors = map[string]bool{
+		"@yearly":   true,
+		"@annually": true,
+		"@monthly":  true,
+		"@weekly":   true,
+		"@daily":    true,
+		"@midnight": true,
+		"@hourly":   true,
+	}
+
+	// imageReferencePattern matches an image reference, following the
+	// grammar used by Docker: an optional registry host, a repository path, and
+	// an optional tag and digest. The repository is the first submatch.
+	imageReferencePattern = func() *regexp.Regexp {
+		const (
+			component       = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+			domainComponent = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+			domain          = domainComponent + `(?:\.` + domainComponent + `)*(?::[0-9]+)?`
+			name            = `(?:` + domain + `/)?` + component + `(?:/` + component + `)*`
+			tag             = `[\w][\w.-]{0,127}`
+			digest          = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9A-Fa-f]{32,}`
+		)
+		return regexp.MustCompile(`^(` + name + `)(?::` + tag + `)?(?:@` + digest + `)?$`)
+	}()
+)
+
+// ValidateHealthCheck returns everything wrong with a HealthCheck's spec. The
+// schedule and image are checked field by field, so that each mistake is
+// reported against the field that caused it.
+func ValidateHealthCheck(hc *healthv1alpha1.HealthCheck) field.ErrorList {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+
+	switch {
+	case hc.Spec.Frequency != "" && hc.Spec.CronPattern != "":
+		errs = append(errs, field.Forbidden(spec.Child("cronPattern"), "only one of frequency or cronPattern may be set"))
+	case hc.Spec.Frequency == "" && hc.Spec.CronPattern == "":
+		errs = append(errs, field.Required(spec.Child("frequency"), "one of frequency or cronPattern must be set"))
+	}
+	if hc.Spec.Frequency != "" {
+		if err := validateFrequency(hc.Spec.Frequency); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("frequency"), hc.Spec.Frequency, err.Error()))
+		}
+	}
+	if hc.Spec.CronPattern != "" {
+		if err := validateCronPattern(hc.Spec.CronPattern); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("cronPattern"), hc.Spec.CronPattern, err.Error()))
+		}
+	}
+	if hc.Spec.Image != "" {
+		if err := validateImage(hc.Spec.Image); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("image"), hc.Spec.Image, err.Error()))
+		}
+	}
+	for i, c := range hc.Spec.Containers {
+		if c.Image == "" {
+			continue
+		}
+		if err := validateImage(c.Image); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("containers").Index(i).Child("image"), c.Image, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// The rest of the spec is checked the same way the controller checks it.
+	if err := controller.ValidateSpec(hc.Spec); err != nil {
+		errs = append(errs, field.Forbidden(spec, err.Error()))
+	}
+	return errs
+}
+
+// validateFrequency checks that a frequency parses and isn't shorter than the
+// controller allows, adding a hint about the expected format when it doesn't
+// look like a frequency at all.
+func validateFrequency(expr string) error {
+	freq, err := frequency.ParseFrequency(expr)
+	if frequency.IsInvalidExpr(err) {
+		return fmt.Errorf("%s, expected amounts followed by a unit of w, d, h, m or s, eg \"1h30m\"", err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	if freq.ToDuration() < controller.MinInterval {
+		return fmt.Errorf("must be at least %s", controller.MinInterval)
+	}
+	return nil
+}
+
+// validateCronPattern checks that a cronPattern is a schedule a CronJob
+// accepts. Unlike cron.ParseStandard, CronJobs don't accept time zones or
+// @every.
+func validateCronPattern(expr string) error {
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return fmt.Errorf("time zones aren't supported by CronJobs")
+	}
+	if strings.HasPrefix(expr, "@") {
+		if !cronJobDescriptors[expr] {
+			return fmt.Errorf("unsupported descriptor, expected one of @yearly, @annually, @monthly, @weekly, @daily, @midnight or @hourly")
+		}
+		return nil
+	}
+	if _, err := cronJobParser.Parse(expr); err != nil {
+		return fmt.Errorf("%s, expected five fields (minute, hour, day of month, month and day of week), eg \"*/5 * * * *\"", err.Error())
+	}
+	return nil
+}
+
+// validateImage checks that an image reference could be pulled.
+func validateImage(image string) error {
+	match := imageReferencePattern.FindStringSubmatch(image)
+	if match == nil {
+		return fmt.Errorf("invalid image reference, expected a repository with an optional tag or digest, eg \"nginx:1.17\"")
+	}
+	if len(match[1]) > maxImageNameLength {
+		return fmt.Errorf("repository name must not be longer than %d characters", maxImageNameLength)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package webhook serves the admission webhooks that validate HealthChecks and
+// ClusterHealthChecks, and fill in their defaults before they are stored.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
+)
+
+const (
+	// ValidatePath is the path the validating webhook is served on.
+	ValidatePath = "/validate"
+	// MutatePath is the path the defaulting webhook is served on.
+	MutatePath = "/mutate"
+
+	// maxRequestSize is the largest AdmissionReview that is read. The API
+	// server doesn't store objects larger than this anyway.
+	maxRequestSize = 3 * 1024 * 1024
+)
+
+// admitFunc decides whether an admission request is allowed.
+type admitFunc func(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse
+
+// NewHandler returns a handler serving the validating webhook on ValidatePath
+// and the defaulting webhook on MutatePath. Defaults that are configurable are
+// taken from cfg.
+func NewHandler(cfg *config.Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(ValidatePath, serve(validate))
+	mux.Handle(MutatePath, serve(mutate(cfg)))
+	return mux
+}
+
+// serve decodes the AdmissionReview in the request, and responds with the
+// result of admit.
+func serve(admit admitFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "expected a POST request", http.StatusMethodNotAllowed)
+			return
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			http.Error(w, fmt.Sprintf("expected Content-Type application/json but got %q", contentType), http.StatusUnsupportedMediaType)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("couldn't read request body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		var review admissionv1beta1.AdmissionReview
+		if err := json.Unmarshal(body, &review); err != nil {
+			http.Error(w, fmt.Sprintf("couldn't decode AdmissionReview: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "AdmissionReview has no request", http.StatusBadRequest)
+			return
+		}
+
+		response := admit(review.Request)
+		response.UID = review.Request.UID
+		review.Response = response
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			klog.Errorf("Error writing admission response: %s", err.Error())
+		}
+	}
+}
+
+// decodeHealthCheck returns the HealthCheck being admitted. A
+// ClusterHealthCheck's spec has the same fields as a HealthCheck's, besides
+// its execution namespace, so it is decoded and checked as a HealthCheck.
+func decodeHealthCheck(req *admissionv1beta1.AdmissionRequest) (*healthv1alpha1.HealthCheck, error) {
+	var hc healthv1alpha1.HealthCheck
+	if err := json.Unmarshal(req.Object.Raw, &hc); err != nil {
+		return nil, fmt.Errorf("couldn't decode HealthCheck: %s", err.Error())
+	}
+	return &hc, nil
+}
+
+// hasSpec returns true if the object being admitted has a spec. Patches can
+// only add fields to a spec that exists.
+func hasSpec(req *admissionv1beta1.AdmissionRequest) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(req.Object.Raw, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["spec"]
+	return ok
+}
+
+// specUnchanged returns true if the request updates an object without changing
+// its spec, as the controller does when it patches annotations. Specs stored
+// before the webhooks were registered may be invalid, and that shouldn't stop
+// their metadata from being updated.
+func specUnchanged(req *admissionv1beta1.AdmissionRequest) bool {
+	if req.Operation != admissionv1beta1.Update || len(req.OldObject.Raw) == 0 {
+		return false
+	}
+	var object, old struct {
+		Spec interface{} `json:"spec"`
+	}
+	if err := json.Unmarshal(req.Object.Raw, &object); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(object.Spec, old.Spec)
+}
+
+// admittedKind returns the kind of the object being admitted.
+func admittedKind(req *admissionv1beta1.AdmissionRequest) schema.GroupKind {
+	if req.Kind.Kind == "" {
+		return healthv1alpha1.SchemeGroupVersion.WithKind("HealthCheck").GroupKind()
+	}
+	return schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind}
+}
+
+// validate denies HealthChecks that the controller wouldn't be able to run.
+func validate(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if specUnchanged(req) {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	hc, err := decodeHealthCheck(req)
+	if err != nil {
+		return errorResponse(apierrors.NewBadRequest(err.Error()))
+	}
+	if errs := ValidateHealthCheck(hc); len(errs) > 0 {
+		return errorResponse(apierrors.NewInvalid(admittedKind(req), hc.Name, errs))
+	}
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+// mutate returns an admitFunc that fills in the defaults of a HealthCheck's
+// spec.
+func mutate(cfg *config.Config) admitFunc {
+	return func(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+		if specUnchanged(req) {
+			return &admissionv1beta1.AdmissionResponse{Allowed: true}
+		}
+		hc, err := decodeHealthCheck(req)
+		if err != nil {
+			return errorResponse(apierrors.NewBadRequest(err.Error()))
+		}
+		patch, err := defaultsPatch(hc, hasSpec(req), cfg)
+		if err != nil {
+			return errorResponse(apierrors.NewInternalError(err))
+		}
+		response := &admissionv1beta1.AdmissionResponse{Allowed: true}
+		if patch != nil {
+			patchType := admissionv1beta1.PatchTypeJSONPatch
+			response.Patch = patch
+			response.PatchType = &patchType
+		}
+		return response
+	}
+}
+
+func errorResponse(err *apierrors.StatusError) *admissionv1beta1.AdmissionResponse {
+	status := err.Status()
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: false,
+		Result:  &status,
+	}
+}
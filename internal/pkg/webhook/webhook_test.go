@@ -0,0 +1,331 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mbellgb/healthcheck-controller/internal/pkg/config"
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newHealthCheck(spec healthv1alpha1.HealthCheckSpec) *healthv1alpha1.HealthCheck {
+	return &healthv1alpha1.HealthCheck{
+		TypeMeta:   metav1.TypeMeta{APIVersion: healthv1alpha1.SchemeGroupVersion.String(), Kind: "HealthCheck"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault},
+		Spec:       spec,
+	}
+}
+
+// review sends an AdmissionReview creating the object to the handler, and
+// returns the response.
+func review(t *testing.T, path string, object interface{}) *admissionv1beta1.AdmissionResponse {
+	return reviewUpdate(t, path, nil, object)
+}
+
+// reviewUpdate sends an AdmissionReview updating old to object to the handler,
+// or creating object if old is nil, and returns the response.
+func reviewUpdate(t *testing.T, path string, old, object interface{}) *admissionv1beta1.AdmissionResponse {
+	raw, err := json.Marshal(object)
+	if err != nil {
+		t.Fatalf("couldn't encode object: %v", err)
+	}
+	request := &admissionv1beta1.AdmissionRequest{
+		UID:       types.UID("1234"),
+		Operation: admissionv1beta1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+	if old != nil {
+		oldRaw, err := json.Marshal(old)
+		if err != nil {
+			t.Fatalf("couldn't encode old object: %v", err)
+		}
+		request.Operation = admissionv1beta1.Update
+		request.OldObject = runtime.RawExtension{Raw: oldRaw}
+	}
+	if obj, ok := object.(runtime.Object); ok {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		request.Kind = metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}
+	}
+	body, err := json.Marshal(admissionv1beta1.AdmissionReview{Request: request})
+	if err != nil {
+		t.Fatalf("couldn't encode AdmissionReview: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	NewHandler(config.Default()).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 but got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var result admissionv1beta1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("couldn't decode response: %v", err)
+	}
+	if result.Response == nil {
+		t.Fatalf("expected a response")
+	}
+	if result.Response.UID != "1234" {
+		t.Errorf("expected the response to have the request's UID, got %q", result.Response.UID)
+	}
+	return result.Response
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	probe := &healthv1alpha1.HTTPProbe{URL: "http://example.com"}
+	tests := []struct {
+		name string
+		spec healthv1alpha1.HealthCheckSpec
+		// errors are the fields expected to be reported, in order.
+		errors []string
+	}{
+		{name: "frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1h30m", HTTP: probe}},
+		{name: "cron", spec: healthv1alpha1.HealthCheckSpec{CronPattern: "*/5 * * * *", HTTP: probe}},
+		{name: "image", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m", Image: "registry.example.com:5000/team/check:v1.2"}},
+		{name: "image digest", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m", Image: "nginx@sha256:" + strings.Repeat("a", 64)}},
+		{name: "both schedules", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m", CronPattern: "* * * * *", HTTP: probe}, errors: []string{"spec.cronPattern"}},
+		{name: "no schedule", spec: healthv1alpha1.HealthCheckSpec{HTTP: probe}, errors: []string{"spec.frequency"}},
+		{name: "bad frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "every hour", HTTP: probe}, errors: []string{"spec.frequency"}},
+		{name: "zero frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "0s", HTTP: probe}, errors: []string{"spec.frequency"}},
+		{name: "sub-second frequency", spec: healthv1alpha1.HealthCheckSpec{Frequency: "0.5s", HTTP: probe}, errors: []string{"spec.frequency"}},
+		{name: "wrong order", spec: healthv1alpha1.HealthCheckSpec{Frequency: "30m1h", HTTP: probe}, errors: []string{"spec.frequency"}},
+		{name: "bad cron", spec: healthv1alpha1.HealthCheckSpec{CronPattern: "* * *", HTTP: probe}, errors: []string{"spec.cronPattern"}},
+		{name: "cron descriptor", spec: healthv1alpha1.HealthCheckSpec{CronPattern: "@hourly", HTTP: probe}},
+		{name: "cron every", spec: healthv1alpha1.HealthCheckSpec{CronPattern: "@every 5m", HTTP: probe}, errors: []string{"spec.cronPattern"}},
+		{name: "cron time zone", spec: healthv1alpha1.HealthCheckSpec{CronPattern: "TZ=Europe/London 0 9 * * *", HTTP: probe}, errors: []string{"spec.cronPattern"}},
+		{name: "bad image", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m", Image: "Nginx:latest"}, errors: []string{"spec.image"}},
+		{
+			name: "bad container image",
+			spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m", Containers: []healthv1alpha1.CheckContainer{
+				{Name: "db", Image: "postgres:12"},
+				{Name: "cache", Image: "redis::6"},
+			}},
+			errors: []string{"spec.containers[1].image"},
+		},
+		{name: "long image", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m", Image: strings.Repeat("a", 256)}, errors: []string{"spec.image"}},
+		{
+			name:   "several",
+			spec:   healthv1alpha1.HealthCheckSpec{Frequency: "soon", Image: "nginx:"},
+			errors: []string{"spec.frequency", "spec.image"},
+		},
+		{name: "no check", spec: healthv1alpha1.HealthCheckSpec{Frequency: "1m"}, errors: []string{"spec"}},
+	}
+	for _, test := range tests {
+		errs := ValidateHealthCheck(newHealthCheck(test.spec))
+		fields := make([]string, 0, len(errs))
+		for _, err := range errs {
+			fields = append(fields, err.Field)
+		}
+		if len(fields) != len(test.errors) || (len(fields) > 0 && !reflect.DeepEqual(fields, test.errors)) {
+			t.Errorf("%s: expected errors for %v but got %v", test.name, test.errors, errs)
+		}
+	}
+}
+
+func TestValidateFrequencyHint(t *testing.T) {
+	err := validateFrequency("every hour")
+	if err == nil || !strings.Contains(err.Error(), `eg "1h30m"`) {
+		t.Errorf("expected the error to show an example frequency, got %v", err)
+	}
+}
+
+func TestServeValidate(t *testing.T) {
+	allowed := review(t, ValidatePath, newHealthCheck(healthv1alpha1.HealthCheckSpec{
+		Frequency: "30s",
+		TCP:       &healthv1alpha1.TCPProbe{Host: "example.com", Port: 443},
+	}))
+	if !allowed.Allowed {
+		t.Errorf("expected a valid HealthCheck to be allowed, got %+v", allowed.Result)
+	}
+
+	denied := review(t, ValidatePath, newHealthCheck(healthv1alpha1.HealthCheckSpec{
+		Frequency: "30x",
+		TCP:       &healthv1alpha1.TCPProbe{Host: "example.com", Port: 443},
+	}))
+	if denied.Allowed {
+		t.Fatalf("expected an invalid HealthCheck to be denied")
+	}
+	if denied.Result == nil || denied.Result.Reason != metav1.StatusReasonInvalid {
+		t.Fatalf("expected an Invalid status, got %+v", denied.Result)
+	}
+	if msg := denied.Result.Message; !strings.Contains(msg, `spec.frequency: Invalid value: "30x"`) {
+		t.Errorf("expected the message to name the invalid field, got %q", msg)
+	}
+}
+
+func TestServeMutate(t *testing.T) {
+	response := review(t, MutatePath, newHealthCheck(healthv1alpha1.HealthCheckSpec{
+		HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com"},
+	}))
+	if !response.Allowed {
+		t.Fatalf("expected the HealthCheck to be allowed, got %+v", response.Result)
+	}
+	if response.PatchType == nil || *response.PatchType != admissionv1beta1.PatchTypeJSONPatch {
+		t.Fatalf("expected a JSON patch, got %v", response.PatchType)
+	}
+	var ops []patchOperation
+	if err := json.Unmarshal(response.Patch, &ops); err != nil {
+		t.Fatalf("couldn't decode patch: %v", err)
+	}
+	expected := []patchOperation{
+		{Op: "add", Path: "/spec/activeDeadlineSeconds", Value: float64(300)},
+		{Op: "add", Path: "/spec/backoffLimit", Value: float64(0)},
+		{Op: "add", Path: "/spec/concurrencyPolicy", Value: "Forbid"},
+		{Op: "add", Path: "/spec/failedJobsHistoryLimit", Value: float64(10)},
+		{Op: "add", Path: "/spec/failureThreshold", Value: float64(1)},
+		{Op: "add", Path: "/spec/http/method", Value: "GET"},
+		{Op: "add", Path: "/spec/startingDeadlineSeconds", Value: float64(10)},
+		{Op: "add", Path: "/spec/successThreshold", Value: float64(1)},
+		{Op: "add", Path: "/spec/successfulJobsHistoryLimit", Value: float64(10)},
+	}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Errorf("expected patch %+v but got %+v", expected, ops)
+	}
+
+	// Nothing is patched once the defaults are filled in.
+	hc := newHealthCheck(healthv1alpha1.HealthCheckSpec{
+		HTTP: &healthv1alpha1.HTTPProbe{URL: "http://example.com"},
+	})
+	patch, err := defaultsPatch(hc, true, config.Default())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var defaulted healthv1alpha1.HealthCheck
+	if err := json.Unmarshal(applyAdds(t, hc, patch), &defaulted); err != nil {
+		t.Fatalf("couldn't decode patched HealthCheck: %v", err)
+	}
+	if patch, err := defaultsPatch(&defaulted, true, config.Default()); err != nil || patch != nil {
+		t.Errorf("expected no patch for a defaulted HealthCheck, got %s (%v)", patch, err)
+	}
+}
+
+func TestServeMetadataUpdate(t *testing.T) {
+	// A spec stored before the webhooks were registered.
+	old := newHealthCheck(healthv1alpha1.HealthCheckSpec{Frequency: "30x"})
+	old.Annotations = map[string]string{healthv1alpha1.RunNowAnnotation: "true"}
+	updated := old.DeepCopy()
+	updated.Annotations = nil
+	for _, path := range []string{ValidatePath, MutatePath} {
+		response := reviewUpdate(t, path, old, updated)
+		if !response.Allowed || response.Patch != nil {
+			t.Errorf("%s: expected an update leaving the spec alone to be allowed unchanged, got %+v", path, response)
+		}
+	}
+
+	updated.Spec.Frequency = "31x"
+	if response := reviewUpdate(t, ValidatePath, old, updated); response.Allowed {
+		t.Errorf("expected an update changing the spec to be validated")
+	}
+}
+
+func TestServeClusterHealthCheck(t *testing.T) {
+	chc := &healthv1alpha1.ClusterHealthCheck{
+		TypeMeta:   metav1.TypeMeta{APIVersion: healthv1alpha1.SchemeGroupVersion.String(), Kind: "ClusterHealthCheck"},
+		ObjectMeta: metav1.ObjectMeta{Name: "apiserver"},
+		Spec: healthv1alpha1.ClusterHealthCheckSpec{
+			HealthCheckSpec:    healthv1alpha1.HealthCheckSpec{Frequency: "30x", HTTP: &healthv1alpha1.HTTPProbe{URL: "https://kubernetes.default.svc/readyz"}},
+			ExecutionNamespace: "kube-system",
+		},
+	}
+	denied := review(t, ValidatePath, chc)
+	if denied.Allowed {
+		t.Fatalf("expected an invalid ClusterHealthCheck to be denied")
+	}
+	if details := denied.Result.Details; details == nil || details.Kind != "ClusterHealthCheck" || details.Name != "apiserver" {
+		t.Errorf("expected the ClusterHealthCheck to be named in the status details, got %+v", details)
+	}
+
+	chc.Spec.Frequency = "30s"
+	response := review(t, MutatePath, chc)
+	if !response.Allowed {
+		t.Fatalf("expected a valid ClusterHealthCheck to be allowed, got %+v", response.Result)
+	}
+	var ops []map[string]interface{}
+	if err := json.Unmarshal(response.Patch, &ops); err != nil {
+		t.Fatalf("couldn't decode patch: %v", err)
+	}
+	for _, op := range ops {
+		if op["path"] == "/spec/executionNamespace" {
+			t.Errorf("expected the execution namespace to be left alone, got %v", op)
+		}
+	}
+	if len(ops) == 0 {
+		t.Errorf("expected the ClusterHealthCheck's defaults to be filled in")
+	}
+}
+
+func TestMutateWithoutSpec(t *testing.T) {
+	response := review(t, MutatePath, map[string]interface{}{
+		"apiVersion": healthv1alpha1.SchemeGroupVersion.String(),
+		"kind":       "HealthCheck",
+		"metadata":   map[string]interface{}{"name": "foo"},
+	})
+	var ops []patchOperation
+	if err := json.Unmarshal(response.Patch, &ops); err != nil {
+		t.Fatalf("couldn't decode patch: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Op != "add" || ops[0].Path != "/spec" {
+		t.Errorf("expected the whole spec to be added, got %+v", ops)
+	}
+}
+
+func TestServeRejectsBadRequests(t *testing.T) {
+	handler := NewHandler(config.Default())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ValidatePath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 for a GET but got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, ValidatePath, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected status 415 for a text body but got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, ValidatePath, strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a review without a request but got %d", rec.Code)
+	}
+}
+
+// applyAdds applies a patch made up of add operations under /spec to the
+// HealthCheck, and returns the patched object as JSON.
+func applyAdds(t *testing.T, hc *healthv1alpha1.HealthCheck, patch []byte) []byte {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		t.Fatalf("couldn't decode patch: %v", err)
+	}
+	object, err := toMap(hc)
+	if err != nil {
+		t.Fatalf("couldn't encode HealthCheck: %v", err)
+	}
+	for _, op := range ops {
+		parts := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+		parent := object
+		for _, part := range parts[:len(parts)-1] {
+			parent = parent[part].(map[string]interface{})
+		}
+		parent[parts[len(parts)-1]] = op.Value
+	}
+	b, err := json.Marshal(object)
+	if err != nil {
+		t.Fatalf("couldn't encode patched HealthCheck: %v", err)
+	}
+	return b
+}
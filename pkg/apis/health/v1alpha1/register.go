@@ -31,6 +31,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&HealthCheck{},
 		&HealthCheckList{},
+		&ClusterHealthCheck{},
+		&ClusterHealthCheckList{},
+		&HealthCheckGroup{},
+		&HealthCheckGroupList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
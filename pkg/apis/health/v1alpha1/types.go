@@ -1,9 +1,16 @@
 package v1alpha1
 
 import (
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// RunNowAnnotation, when set on a HealthCheck, makes the controller start a
+// run immediately instead of waiting for the next scheduled one. The
+// controller removes the annotation once the run has started.
+const RunNowAnnotation = "health.mbell.dev/run-now"
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -18,18 +25,436 @@ type HealthCheck struct {
 
 // HealthCheckSpec defines the specification of a HealthCheck resource.
 type HealthCheckSpec struct {
-	Image       string   `json:"image"`
+	Image       string   `json:"image,omitempty"`
 	Frequency   string   `json:"frequency,omitempty"`
 	CronPattern string   `json:"cronPattern,omitempty"`
 	Args        []string `json:"args,omitempty"`
+	// Template customises the Pods that run the check. It is merged with
+	// the controller's defaults: a container named "healthcheck" is
+	// configured from Image and Args, or runs the built-in probe, and the
+	// controller's label and restart policy always apply.
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+
+	// ActiveDeadlineSeconds is how long a run may take before it is stopped
+	// and counted as a failure. Defaults to 300.
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// BackoffLimit is the number of times a failed run is retried before it
+	// counts as a failure. Defaults to 0.
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// ConcurrencyPolicy says what happens when a run is due while the
+	// previous one is still going. Defaults to Forbid.
+	ConcurrencyPolicy batchv1beta1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// StartingDeadlineSeconds is how late a run may start after its
+	// scheduled time before it is skipped. Defaults to 10.
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// SuccessfulJobsHistoryLimit is the number of passed runs whose Jobs are
+	// kept. Defaults to 10, and must be at least 1 so that every result is
+	// recorded before its Job is deleted.
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+	// FailedJobsHistoryLimit is the number of failed runs whose Jobs are
+	// kept. Defaults to 10, and must be at least 1.
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// Suspend stops new runs while it is true. Runs that have already
+	// started are still recorded.
+	Suspend bool `json:"suspend,omitempty"`
+	// MaintenanceWindows are recurring periods of planned downtime. No runs
+	// are started during them, and the results of runs finishing during them
+	// are ignored.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// HTTP configures a built-in HTTP probe, run by the controller's checker
+	// instead of a user supplied Image.
+	HTTP *HTTPProbe `json:"http,omitempty"`
+	// TCP configures a built-in TCP probe.
+	TCP *TCPProbe `json:"tcp,omitempty"`
+	// DNS configures a built-in DNS probe.
+	DNS *DNSProbe `json:"dns,omitempty"`
+	// Steps configure a scripted check, a sequence of HTTP requests made by
+	// the checker in a single run. Values captured from earlier responses can
+	// be used in later requests.
+	Steps []Step `json:"steps,omitempty"`
+	// Containers run several checks in one Pod, such as one for each
+	// dependency of a service, instead of a single Image or probe.
+	Containers []CheckContainer `json:"containers,omitempty"`
+	// ContainerPolicy is whether Containers run in Parallel, or are
+	// Sequential. Defaults to Parallel.
+	ContainerPolicy ContainerPolicy `json:"containerPolicy,omitempty"`
+	// Pods derives health from the state of existing Pods rather than
+	// running a check.
+	Pods *PodsProbe `json:"pods,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed runs after which
+	// a healthy HealthCheck becomes unhealthy. Defaults to 1, and can be at
+	// most 10.
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+	// SuccessThreshold is the number of consecutive passed runs after which
+	// an unhealthy HealthCheck becomes healthy. Defaults to 1, and can be at
+	// most 10.
+	SuccessThreshold int32 `json:"successThreshold,omitempty"`
+	// MinAverageHealthiness, if set, is the lowest AverageHealthiness at
+	// which the HealthCheck can be healthy.
+	MinAverageHealthiness float32 `json:"minAverageHealthiness,omitempty"`
+
+	// Alerting configures notifications sent when the HealthCheck becomes
+	// unhealthy, or healthy again.
+	Alerting *Alerting `json:"alerting,omitempty"`
+}
+
+// HTTPProbe describes an HTTP request made by the checker, and the response
+// expected for the check to pass.
+type HTTPProbe struct {
+	URL string `json:"url"`
+	// Method is the HTTP method to use. Defaults to GET.
+	Method string `json:"method,omitempty"`
+	// Headers are sent with the request. They're passed to the checker in
+	// the check Pod's arguments, so anyone who can get the Pod can read
+	// them. Use HeadersFrom for credentials.
+	Headers map[string]string `json:"headers,omitempty"`
+	// HeadersFrom are headers whose values are read from Secrets. They're
+	// passed to the checker in environment variables that reference the
+	// Secret, so their values aren't in the Pod spec. They replace any
+	// header of the same name in Headers.
+	HeadersFrom []HTTPHeaderSource `json:"headersFrom,omitempty"`
+	// Body is sent as the request body.
+	Body string `json:"body,omitempty"`
+	// ExpectedStatusCodes are the response codes that pass the check.
+	// Defaults to any code from 200 to 399.
+	ExpectedStatusCodes []int32 `json:"expectedStatusCodes,omitempty"`
+	// BodyRegex, if set, must match the response body for the check to pass.
+	BodyRegex string `json:"bodyRegex,omitempty"`
+	// TimeoutSeconds is how long to wait for a response. Defaults to 10.
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// InsecureSkipVerify disables verification of the server's TLS
+	// certificate.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// FollowRedirects is whether redirects are followed, checking the final
+	// response. Defaults to true, unless ExpectedStatusCodes has a redirect
+	// code.
+	FollowRedirects *bool `json:"followRedirects,omitempty"`
+}
+
+// HTTPHeaderSource is a request header whose value is read from a Secret.
+type HTTPHeaderSource struct {
+	Name      string                `json:"name"`
+	ValueFrom HTTPHeaderValueSource `json:"valueFrom"`
+}
+
+// HTTPHeaderValueSource selects the value of a header.
+type HTTPHeaderValueSource struct {
+	// SecretKeyRef selects a key of a Secret in the namespace the check
+	// Pods run in.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// Step is one request of a scripted check.
+type Step struct {
+	// Name identifies the step in the check's results.
+	Name string `json:"name"`
+	// HTTP is the request the step makes, and the response expected. Values
+	// captured by earlier steps are substituted for ${name} in its URL,
+	// header values and body.
+	HTTP HTTPProbe `json:"http"`
+	// Assertions are further checks on the response, made once its status
+	// code and body have been checked.
+	Assertions []StepAssertion `json:"assertions,omitempty"`
+	// Capture are values read from the response for later steps to use.
+	Capture []StepCapture `json:"capture,omitempty"`
+}
+
+// ResponseValue selects a value from an HTTP response. Exactly one field
+// should be set.
+type ResponseValue struct {
+	// JSONPath selects a value from a JSON response body, such as
+	// `.data.token` or `{.items[0].id}`. It must select exactly one value.
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Header selects the value of a response header.
+	Header string `json:"header,omitempty"`
+}
+
+// StepAssertion checks a value from a step's response. Without Equals or
+// Matches, the value only has to be present.
+type StepAssertion struct {
+	ResponseValue `json:",inline"`
+	// Equals, if set, must be the value.
+	Equals string `json:"equals,omitempty"`
+	// Matches, if set, is a regular expression the value must match.
+	Matches string `json:"matches,omitempty"`
+}
+
+// StepCapture stores a value from a step's response in a variable.
+type StepCapture struct {
+	// Name is the name of the variable, used as ${name} in later steps.
+	Name          string `json:"name"`
+	ResponseValue `json:",inline"`
+}
+
+// ContainerPolicy describes how a HealthCheck's check containers are run.
+type ContainerPolicy string
+
+const (
+	// ParallelContainers runs every check container at once. The check
+	// passes if they all do.
+	ParallelContainers ContainerPolicy = "Parallel"
+	// SequentialContainers runs the check containers one after another, as
+	// init containers, and stops at the first that fails.
+	SequentialContainers ContainerPolicy = "Sequential"
+)
+
+// CheckContainer is one of several checks run in a HealthCheck's Pod.
+// Exactly one of Image, HTTP, TCP or DNS should be set.
+type CheckContainer struct {
+	// Name is the name of the container, which a pod template can use to
+	// customise it.
+	Name string `json:"name"`
+	// Image is a user supplied image that runs the check.
+	Image string `json:"image,omitempty"`
+	// Args are passed to Image.
+	Args []string `json:"args,omitempty"`
+	// HTTP configures a built-in HTTP probe.
+	HTTP *HTTPProbe `json:"http,omitempty"`
+	// TCP configures a built-in TCP probe.
+	TCP *TCPProbe `json:"tcp,omitempty"`
+	// DNS configures a built-in DNS probe.
+	DNS *DNSProbe `json:"dns,omitempty"`
+}
+
+// TCPProbe describes a TCP connection made by the checker.
+type TCPProbe struct {
+	Host string `json:"host"`
+	Port int32  `json:"port"`
+	// TimeoutSeconds is how long to wait for the connection, and for any
+	// expected data. Defaults to 10.
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// Send is written to the connection once it is open.
+	Send string `json:"send,omitempty"`
+	// Expect, if set, must be read back from the connection for the check to
+	// pass.
+	Expect string `json:"expect,omitempty"`
+}
+
+// DNSProbe describes a DNS lookup made by the checker.
+type DNSProbe struct {
+	Name string `json:"name"`
+	// RecordType is the type of record to look up, one of A, AAAA, CNAME,
+	// MX, NS, SRV or TXT. Defaults to A.
+	RecordType string `json:"recordType,omitempty"`
+	// ExpectedAnswers, if set, must all be in the lookup's answers for the
+	// check to pass. Otherwise any answer passes.
+	ExpectedAnswers []string `json:"expectedAnswers,omitempty"`
+	// Resolver is the address of the DNS server to query, as host:port.
+	// Defaults to the Pod's resolver.
+	Resolver string `json:"resolver,omitempty"`
+	// TimeoutSeconds is how long to wait for the lookup. Defaults to 10.
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// PodsProbe describes the Pods whose readiness and restarts are sampled by
+// the controller. A sample passes if enough of the Pods are ready and they
+// haven't restarted too often since the previous sample.
+type PodsProbe struct {
+	// Selector selects the Pods in the HealthCheck's namespace to sample.
+	Selector metav1.LabelSelector `json:"selector"`
+	// MinReady is the number of Pods that must be ready. Defaults to all of
+	// the selected Pods, and at least one.
+	MinReady int32 `json:"minReady,omitempty"`
+	// MaxRestarts is the number of container restarts, across all selected
+	// Pods, allowed between two samples. Defaults to 0.
+	MaxRestarts int32 `json:"maxRestarts,omitempty"`
+}
+
+// Alerting configures the notifications sent for a HealthCheck.
+type Alerting struct {
+	Receivers []AlertReceiver `json:"receivers"`
+	// RepeatInterval is how often to notify receivers again while the
+	// HealthCheck stays unhealthy, as a frequency (eg `4h`). By default
+	// receivers are only notified once.
+	RepeatInterval string `json:"repeatInterval,omitempty"`
+}
+
+// AlertReceiverType is the kind of service an AlertReceiver notifies.
+type AlertReceiverType string
+
+const (
+	// AlertReceiverWebhook posts a generic JSON payload.
+	AlertReceiverWebhook AlertReceiverType = "webhook"
+	// AlertReceiverSlack posts to a Slack compatible incoming webhook.
+	AlertReceiverSlack AlertReceiverType = "slack"
+	// AlertReceiverPagerDuty sends PagerDuty Events API v2 events.
+	AlertReceiverPagerDuty AlertReceiverType = "pagerduty"
+)
+
+// AlertReceiver describes where to send a HealthCheck's notifications.
+type AlertReceiver struct {
+	// Name identifies the receiver. It must be unique within the HealthCheck.
+	Name string            `json:"name"`
+	Type AlertReceiverType `json:"type"`
+	// URL is the address notifications are sent to. PagerDuty receivers
+	// default to the Events API v2 endpoint.
+	URL string `json:"url,omitempty"`
+	// URLSecretRef reads the URL from a Secret in the HealthCheck's
+	// namespace, for URLs that contain credentials.
+	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+	// RoutingKeySecretRef reads the routing key of a PagerDuty receiver
+	// from a Secret in the HealthCheck's namespace.
+	RoutingKeySecretRef *corev1.SecretKeySelector `json:"routingKeySecretRef,omitempty"`
+	// Template is a Go template for the notification's message. It is
+	// given the alert, with fields Namespace, Name, Resolved,
+	// AverageHealthiness, Last10 and Time.
+	Template string `json:"template,omitempty"`
+	// SendResolved sends a notification when the HealthCheck becomes
+	// healthy again.
+	SendResolved bool `json:"sendResolved,omitempty"`
 }
 
 // HealthCheckStatus defines the status object of a HealthCheck resource.
 type HealthCheckStatus struct {
+	// ObservedGeneration is the most recent HealthCheck generation the
+	// controller has acted on.
+	ObservedGeneration int64   `json:"observedGeneration,omitempty"`
 	CronJobName        string  `json:"cronJobName,omitempty"`
 	Healthy            bool    `json:"healthy,omitempty"`
 	Last10             []bool  `json:"last10,omitempty"`
 	AverageHealthiness float32 `json:"averageHealthiness,omitempty"`
+	// ThresholdState is whether the recent runs last reached
+	// SuccessThreshold or FailureThreshold, regardless of
+	// MinAverageHealthiness. It is empty until a new HealthCheck reaches
+	// either, and no alerts are sent until then.
+	ThresholdState ThresholdState `json:"thresholdState,omitempty"`
+	// LastRunTime is the time at which the most recently recorded check run
+	// finished. Runs finishing at or before this time have already been
+	// counted in Last10.
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	// ObservedRestarts is the total number of container restarts of the
+	// selected Pods at the last sample of a Pods probe.
+	ObservedRestarts int32 `json:"observedRestarts,omitempty"`
+	// LastFailureReason is why the most recently recorded failed run
+	// failed: CheckFailed, or CheckTimedOut if it didn't finish within
+	// ActiveDeadlineSeconds.
+	LastFailureReason string `json:"lastFailureReason,omitempty"`
+	// LastResult is the most recently recorded run.
+	LastResult *CheckResult `json:"lastResult,omitempty"`
+	// History is the recorded runs in the same order as Last10, newest
+	// first.
+	History []CheckResult `json:"history,omitempty"`
+	// Conditions are the latest observations of the HealthCheck's state.
+	Conditions []HealthCheckCondition `json:"conditions,omitempty"`
+	// Alerts record the notifications sent to each receiver, so that they
+	// aren't repeated.
+	Alerts []AlertStatus `json:"alerts,omitempty"`
+}
+
+// ThresholdState is the state a HealthCheck's recent runs last reached a
+// threshold for.
+type ThresholdState string
+
+const (
+	// ThresholdPassing means SuccessThreshold consecutive runs passed.
+	ThresholdPassing ThresholdState = "Passing"
+	// ThresholdFailing means FailureThreshold consecutive runs failed.
+	ThresholdFailing ThresholdState = "Failing"
+)
+
+// CheckResult is the outcome of a single run.
+type CheckResult struct {
+	// JobName is the Job the check ran in. It is empty for Pods probes.
+	JobName string `json:"jobName,omitempty"`
+	Passed  bool   `json:"passed"`
+	// Message is the check container's termination message, or the end of
+	// its log if it failed without one, truncated to 1024 bytes.
+	Message  string `json:"message,omitempty"`
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// DurationMilliseconds is how long the run took, if known.
+	DurationMilliseconds int64        `json:"durationMilliseconds,omitempty"`
+	StartTime            *metav1.Time `json:"startTime,omitempty"`
+	FinishTime           metav1.Time  `json:"finishTime"`
+	// FailedStep is the name of the step a scripted check failed at.
+	FailedStep string `json:"failedStep,omitempty"`
+	// Steps are the results of the steps of a scripted check, up to the
+	// first that failed.
+	Steps []StepResult `json:"steps,omitempty"`
+	// Containers are the results of each check container that ran, for
+	// HealthChecks with several.
+	Containers []ContainerResult `json:"containers,omitempty"`
+}
+
+// ContainerResult is the outcome of one check container.
+type ContainerResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Message is the container's termination message, truncated to 1024
+	// bytes.
+	Message  string `json:"message,omitempty"`
+	ExitCode int32  `json:"exitCode"`
+}
+
+// StepResult is the outcome of one step of a scripted check.
+type StepResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+	// StatusCode is the response code of the step's request, if it got a
+	// response.
+	StatusCode int32 `json:"statusCode,omitempty"`
+	// DurationMilliseconds is how long the step took.
+	DurationMilliseconds int64 `json:"durationMilliseconds"`
+}
+
+// MaintenanceWindow is a recurring period of planned downtime.
+type MaintenanceWindow struct {
+	// Schedule is when each window starts, in cron format.
+	Schedule string `json:"schedule"`
+	// Duration is how long each window lasts, as a period of time (eg `2h`).
+	Duration string `json:"duration"`
+	// TimeZone is the IANA name of the time zone Schedule is in, such as
+	// Europe/London. Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// AlertStatus records the last notification sent to a receiver.
+type AlertStatus struct {
+	Receiver string `json:"receiver"`
+	// Firing is true if the receiver was last told the HealthCheck is
+	// unhealthy.
+	Firing       bool         `json:"firing,omitempty"`
+	LastSentTime *metav1.Time `json:"lastSentTime,omitempty"`
+}
+
+// HealthCheckConditionType is the type of a HealthCheckCondition.
+type HealthCheckConditionType string
+
+const (
+	// HealthCheckReady means the HealthCheck is running and its latest
+	// result is healthy.
+	HealthCheckReady HealthCheckConditionType = "Ready"
+	// HealthCheckScheduled means the HealthCheck's runs are scheduled, either
+	// by a CronJob or by the controller.
+	HealthCheckScheduled HealthCheckConditionType = "Scheduled"
+	// HealthCheckDegraded means some of the HealthCheck's recent runs failed.
+	HealthCheckDegraded HealthCheckConditionType = "Degraded"
+	// HealthCheckInvalidSpec means the HealthCheck's spec can't be run.
+	HealthCheckInvalidSpec HealthCheckConditionType = "InvalidSpec"
+	// HealthCheckResourceConflict means a resource the HealthCheck needs
+	// already exists and is managed by something else.
+	HealthCheckResourceConflict HealthCheckConditionType = "ResourceConflict"
+	// HealthCheckSuspended means no new runs are started because the
+	// HealthCheck is suspended.
+	HealthCheckSuspended HealthCheckConditionType = "Suspended"
+	// HealthCheckInMaintenance means the HealthCheck is in one of its
+	// maintenance windows.
+	HealthCheckInMaintenance HealthCheckConditionType = "InMaintenance"
+)
+
+// HealthCheckCondition describes one aspect of a HealthCheck's state.
+type HealthCheckCondition struct {
+	Type   HealthCheckConditionType `json:"type"`
+	Status corev1.ConditionStatus   `json:"status"`
+	// ObservedGeneration is the HealthCheck generation the condition was
+	// set for.
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -41,3 +466,100 @@ type HealthCheckList struct {
 
 	Items []HealthCheck `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterHealthCheck is a HealthCheck that doesn't belong to any namespace,
+// for checking cluster-wide concerns. Its Jobs and Pods run in its execution
+// namespace.
+type ClusterHealthCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterHealthCheckSpec `json:"spec"`
+	Status HealthCheckStatus      `json:"status"`
+}
+
+// ClusterHealthCheckSpec defines the specification of a ClusterHealthCheck
+// resource.
+type ClusterHealthCheckSpec struct {
+	HealthCheckSpec `json:",inline"`
+	// ExecutionNamespace is the namespace the check's Jobs and Pods run in.
+	// Pods sampled by a pods check, and Secrets referenced by its alerting,
+	// are also read from it. Defaults to the controller's
+	// -cluster-check-namespace.
+	ExecutionNamespace string `json:"executionNamespace,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterHealthCheckList is a list of ClusterHealthCheck resources.
+type ClusterHealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ClusterHealthCheck `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HealthCheckGroup aggregates the health of the HealthChecks it selects.
+type HealthCheckGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HealthCheckGroupSpec   `json:"spec"`
+	Status HealthCheckGroupStatus `json:"status"`
+}
+
+// HealthCheckGroupSpec defines the specification of a HealthCheckGroup
+// resource.
+type HealthCheckGroupSpec struct {
+	// Selector selects the HealthChecks in the group's namespace that are
+	// members of the group.
+	Selector metav1.LabelSelector `json:"selector"`
+	// Quorum is the number of members that must be healthy for the group to
+	// be healthy. Defaults to all of them.
+	Quorum int32 `json:"quorum,omitempty"`
+	// Weights are the weights given to members, by name, when computing the
+	// group's score. Members not listed have a weight of 1, and weights
+	// can't be negative. If every member has a weight of 0, they count
+	// equally.
+	Weights map[string]int32 `json:"weights,omitempty"`
+	// MinScore, if set, is the lowest score at which the group is healthy.
+	MinScore float32 `json:"minScore,omitempty"`
+}
+
+// HealthCheckGroupStatus defines the status object of a HealthCheckGroup
+// resource.
+type HealthCheckGroupStatus struct {
+	// ObservedGeneration is the most recent HealthCheckGroup generation the
+	// controller has acted on.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Healthy is true if there are members, a quorum of them are healthy,
+	// and the score is at least MinScore.
+	Healthy bool `json:"healthy,omitempty"`
+	// AllHealthy is true if there are members and all of them are healthy.
+	AllHealthy bool `json:"allHealthy,omitempty"`
+	// QuorumReached is true if at least Quorum members are healthy.
+	QuorumReached  bool  `json:"quorumReached,omitempty"`
+	Members        int32 `json:"members,omitempty"`
+	HealthyMembers int32 `json:"healthyMembers,omitempty"`
+	// Score is the average healthiness of the members, weighted by Weights.
+	Score float32 `json:"score,omitempty"`
+	// FailingMembers are the names of the members that aren't healthy.
+	FailingMembers []string `json:"failingMembers,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// HealthCheckGroupList is a list of HealthCheckGroup resources.
+type HealthCheckGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []HealthCheckGroup `json:"items"`
+}
@@ -0,0 +1,164 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	scheme "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ClusterHealthChecksGetter has a method to return a ClusterHealthCheckInterface.
+// A group's client should implement this interface.
+type ClusterHealthChecksGetter interface {
+	ClusterHealthChecks() ClusterHealthCheckInterface
+}
+
+// ClusterHealthCheckInterface has methods to work with ClusterHealthCheck resources.
+type ClusterHealthCheckInterface interface {
+	Create(*v1alpha1.ClusterHealthCheck) (*v1alpha1.ClusterHealthCheck, error)
+	Update(*v1alpha1.ClusterHealthCheck) (*v1alpha1.ClusterHealthCheck, error)
+	UpdateStatus(*v1alpha1.ClusterHealthCheck) (*v1alpha1.ClusterHealthCheck, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.ClusterHealthCheck, error)
+	List(opts v1.ListOptions) (*v1alpha1.ClusterHealthCheckList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterHealthCheck, err error)
+	ClusterHealthCheckExpansion
+}
+
+// clusterHealthChecks implements ClusterHealthCheckInterface
+type clusterHealthChecks struct {
+	client rest.Interface
+}
+
+// newClusterHealthChecks returns a ClusterHealthChecks
+func newClusterHealthChecks(c *HealthV1alpha1Client) *clusterHealthChecks {
+	return &clusterHealthChecks{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the clusterHealthCheck, and returns the corresponding clusterHealthCheck object, and an error if there is any.
+func (c *clusterHealthChecks) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterHealthCheck, err error) {
+	result = &v1alpha1.ClusterHealthCheck{}
+	err = c.client.Get().
+		Resource("clusterhealthchecks").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ClusterHealthChecks that match those selectors.
+func (c *clusterHealthChecks) List(opts v1.ListOptions) (result *v1alpha1.ClusterHealthCheckList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.ClusterHealthCheckList{}
+	err = c.client.Get().
+		Resource("clusterhealthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested clusterHealthChecks.
+func (c *clusterHealthChecks) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("clusterhealthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a clusterHealthCheck and creates it.  Returns the server's representation of the clusterHealthCheck, and an error, if there is any.
+func (c *clusterHealthChecks) Create(clusterHealthCheck *v1alpha1.ClusterHealthCheck) (result *v1alpha1.ClusterHealthCheck, err error) {
+	result = &v1alpha1.ClusterHealthCheck{}
+	err = c.client.Post().
+		Resource("clusterhealthchecks").
+		Body(clusterHealthCheck).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a clusterHealthCheck and updates it. Returns the server's representation of the clusterHealthCheck, and an error, if there is any.
+func (c *clusterHealthChecks) Update(clusterHealthCheck *v1alpha1.ClusterHealthCheck) (result *v1alpha1.ClusterHealthCheck, err error) {
+	result = &v1alpha1.ClusterHealthCheck{}
+	err = c.client.Put().
+		Resource("clusterhealthchecks").
+		Name(clusterHealthCheck.Name).
+		Body(clusterHealthCheck).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *clusterHealthChecks) UpdateStatus(clusterHealthCheck *v1alpha1.ClusterHealthCheck) (result *v1alpha1.ClusterHealthCheck, err error) {
+	result = &v1alpha1.ClusterHealthCheck{}
+	err = c.client.Put().
+		Resource("clusterhealthchecks").
+		Name(clusterHealthCheck.Name).
+		SubResource("status").
+		Body(clusterHealthCheck).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the clusterHealthCheck and deletes it. Returns an error if one occurs.
+func (c *clusterHealthChecks) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("clusterhealthchecks").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *clusterHealthChecks) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("clusterhealthchecks").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched clusterHealthCheck.
+func (c *clusterHealthChecks) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterHealthCheck, err error) {
+	result = &v1alpha1.ClusterHealthCheck{}
+	err = c.client.Patch(pt).
+		Resource("clusterhealthchecks").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,115 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeClusterHealthChecks implements ClusterHealthCheckInterface
+type FakeClusterHealthChecks struct {
+	Fake *FakeHealthV1alpha1
+}
+
+var clusterhealthchecksResource = schema.GroupVersionResource{Group: "health.mbell.dev", Version: "v1alpha1", Resource: "clusterhealthchecks"}
+
+var clusterhealthchecksKind = schema.GroupVersionKind{Group: "health.mbell.dev", Version: "v1alpha1", Kind: "ClusterHealthCheck"}
+
+// Get takes name of the clusterHealthCheck, and returns the corresponding clusterHealthCheck object, and an error if there is any.
+func (c *FakeClusterHealthChecks) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterHealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(clusterhealthchecksResource, name), &v1alpha1.ClusterHealthCheck{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterHealthCheck), err
+}
+
+// List takes label and field selectors, and returns the list of ClusterHealthChecks that match those selectors.
+func (c *FakeClusterHealthChecks) List(opts v1.ListOptions) (result *v1alpha1.ClusterHealthCheckList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(clusterhealthchecksResource, clusterhealthchecksKind, opts), &v1alpha1.ClusterHealthCheckList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ClusterHealthCheckList{ListMeta: obj.(*v1alpha1.ClusterHealthCheckList).ListMeta}
+	for _, item := range obj.(*v1alpha1.ClusterHealthCheckList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested clusterHealthChecks.
+func (c *FakeClusterHealthChecks) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(clusterhealthchecksResource, opts))
+}
+
+// Create takes the representation of a clusterHealthCheck and creates it.  Returns the server's representation of the clusterHealthCheck, and an error, if there is any.
+func (c *FakeClusterHealthChecks) Create(clusterHealthCheck *v1alpha1.ClusterHealthCheck) (result *v1alpha1.ClusterHealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(clusterhealthchecksResource, clusterHealthCheck), &v1alpha1.ClusterHealthCheck{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterHealthCheck), err
+}
+
+// Update takes the representation of a clusterHealthCheck and updates it. Returns the server's representation of the clusterHealthCheck, and an error, if there is any.
+func (c *FakeClusterHealthChecks) Update(clusterHealthCheck *v1alpha1.ClusterHealthCheck) (result *v1alpha1.ClusterHealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(clusterhealthchecksResource, clusterHealthCheck), &v1alpha1.ClusterHealthCheck{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterHealthCheck), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeClusterHealthChecks) UpdateStatus(clusterHealthCheck *v1alpha1.ClusterHealthCheck) (*v1alpha1.ClusterHealthCheck, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(clusterhealthchecksResource, "status", clusterHealthCheck), &v1alpha1.ClusterHealthCheck{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterHealthCheck), err
+}
+
+// Delete takes name of the clusterHealthCheck and deletes it. Returns an error if one occurs.
+func (c *FakeClusterHealthChecks) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(clusterhealthchecksResource, name), &v1alpha1.ClusterHealthCheck{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeClusterHealthChecks) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(clusterhealthchecksResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ClusterHealthCheckList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched clusterHealthCheck.
+func (c *FakeClusterHealthChecks) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterHealthCheck, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(clusterhealthchecksResource, name, pt, data, subresources...), &v1alpha1.ClusterHealthCheck{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterHealthCheck), err
+}
@@ -12,10 +12,18 @@ type FakeHealthV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeHealthV1alpha1) ClusterHealthChecks() v1alpha1.ClusterHealthCheckInterface {
+	return &FakeClusterHealthChecks{c}
+}
+
 func (c *FakeHealthV1alpha1) HealthChecks(namespace string) v1alpha1.HealthCheckInterface {
 	return &FakeHealthChecks{c, namespace}
 }
 
+func (c *FakeHealthV1alpha1) HealthCheckGroups(namespace string) v1alpha1.HealthCheckGroupInterface {
+	return &FakeHealthCheckGroups{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeHealthV1alpha1) RESTClient() rest.Interface {
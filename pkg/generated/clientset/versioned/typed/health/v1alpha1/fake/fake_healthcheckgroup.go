@@ -0,0 +1,124 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeHealthCheckGroups implements HealthCheckGroupInterface
+type FakeHealthCheckGroups struct {
+	Fake *FakeHealthV1alpha1
+	ns   string
+}
+
+var healthcheckgroupsResource = schema.GroupVersionResource{Group: "health.mbell.dev", Version: "v1alpha1", Resource: "healthcheckgroups"}
+
+var healthcheckgroupsKind = schema.GroupVersionKind{Group: "health.mbell.dev", Version: "v1alpha1", Kind: "HealthCheckGroup"}
+
+// Get takes name of the healthCheckGroup, and returns the corresponding healthCheckGroup object, and an error if there is any.
+func (c *FakeHealthCheckGroups) Get(name string, options v1.GetOptions) (result *v1alpha1.HealthCheckGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(healthcheckgroupsResource, c.ns, name), &v1alpha1.HealthCheckGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HealthCheckGroup), err
+}
+
+// List takes label and field selectors, and returns the list of HealthCheckGroups that match those selectors.
+func (c *FakeHealthCheckGroups) List(opts v1.ListOptions) (result *v1alpha1.HealthCheckGroupList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(healthcheckgroupsResource, healthcheckgroupsKind, c.ns, opts), &v1alpha1.HealthCheckGroupList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.HealthCheckGroupList{ListMeta: obj.(*v1alpha1.HealthCheckGroupList).ListMeta}
+	for _, item := range obj.(*v1alpha1.HealthCheckGroupList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested healthCheckGroups.
+func (c *FakeHealthCheckGroups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(healthcheckgroupsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a healthCheckGroup and creates it.  Returns the server's representation of the healthCheckGroup, and an error, if there is any.
+func (c *FakeHealthCheckGroups) Create(healthCheckGroup *v1alpha1.HealthCheckGroup) (result *v1alpha1.HealthCheckGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(healthcheckgroupsResource, c.ns, healthCheckGroup), &v1alpha1.HealthCheckGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HealthCheckGroup), err
+}
+
+// Update takes the representation of a healthCheckGroup and updates it. Returns the server's representation of the healthCheckGroup, and an error, if there is any.
+func (c *FakeHealthCheckGroups) Update(healthCheckGroup *v1alpha1.HealthCheckGroup) (result *v1alpha1.HealthCheckGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(healthcheckgroupsResource, c.ns, healthCheckGroup), &v1alpha1.HealthCheckGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HealthCheckGroup), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeHealthCheckGroups) UpdateStatus(healthCheckGroup *v1alpha1.HealthCheckGroup) (*v1alpha1.HealthCheckGroup, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(healthcheckgroupsResource, "status", c.ns, healthCheckGroup), &v1alpha1.HealthCheckGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HealthCheckGroup), err
+}
+
+// Delete takes name of the healthCheckGroup and deletes it. Returns an error if one occurs.
+func (c *FakeHealthCheckGroups) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(healthcheckgroupsResource, c.ns, name), &v1alpha1.HealthCheckGroup{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeHealthCheckGroups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(healthcheckgroupsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.HealthCheckGroupList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched healthCheckGroup.
+func (c *FakeHealthCheckGroups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.HealthCheckGroup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(healthcheckgroupsResource, c.ns, name, pt, data, subresources...), &v1alpha1.HealthCheckGroup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.HealthCheckGroup), err
+}
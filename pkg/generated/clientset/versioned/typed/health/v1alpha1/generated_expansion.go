@@ -2,4 +2,8 @@
 
 package v1alpha1
 
+type ClusterHealthCheckExpansion interface{}
+
 type HealthCheckExpansion interface{}
+
+type HealthCheckGroupExpansion interface{}
@@ -10,7 +10,9 @@ import (
 
 type HealthV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterHealthChecksGetter
 	HealthChecksGetter
+	HealthCheckGroupsGetter
 }
 
 // HealthV1alpha1Client is used to interact with features provided by the health.mbell.dev group.
@@ -18,10 +20,18 @@ type HealthV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *HealthV1alpha1Client) ClusterHealthChecks() ClusterHealthCheckInterface {
+	return newClusterHealthChecks(c)
+}
+
 func (c *HealthV1alpha1Client) HealthChecks(namespace string) HealthCheckInterface {
 	return newHealthChecks(c, namespace)
 }
 
+func (c *HealthV1alpha1Client) HealthCheckGroups(namespace string) HealthCheckGroupInterface {
+	return newHealthCheckGroups(c, namespace)
+}
+
 // NewForConfig creates a new HealthV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*HealthV1alpha1Client, error) {
 	config := *c
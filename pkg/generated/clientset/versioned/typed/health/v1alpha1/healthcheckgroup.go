@@ -0,0 +1,175 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	scheme "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// HealthCheckGroupsGetter has a method to return a HealthCheckGroupInterface.
+// A group's client should implement this interface.
+type HealthCheckGroupsGetter interface {
+	HealthCheckGroups(namespace string) HealthCheckGroupInterface
+}
+
+// HealthCheckGroupInterface has methods to work with HealthCheckGroup resources.
+type HealthCheckGroupInterface interface {
+	Create(*v1alpha1.HealthCheckGroup) (*v1alpha1.HealthCheckGroup, error)
+	Update(*v1alpha1.HealthCheckGroup) (*v1alpha1.HealthCheckGroup, error)
+	UpdateStatus(*v1alpha1.HealthCheckGroup) (*v1alpha1.HealthCheckGroup, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.HealthCheckGroup, error)
+	List(opts v1.ListOptions) (*v1alpha1.HealthCheckGroupList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.HealthCheckGroup, err error)
+	HealthCheckGroupExpansion
+}
+
+// healthCheckGroups implements HealthCheckGroupInterface
+type healthCheckGroups struct {
+	client rest.Interface
+	ns     string
+}
+
+// newHealthCheckGroups returns a HealthCheckGroups
+func newHealthCheckGroups(c *HealthV1alpha1Client, namespace string) *healthCheckGroups {
+	return &healthCheckGroups{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the healthCheckGroup, and returns the corresponding healthCheckGroup object, and an error if there is any.
+func (c *healthCheckGroups) Get(name string, options v1.GetOptions) (result *v1alpha1.HealthCheckGroup, err error) {
+	result = &v1alpha1.HealthCheckGroup{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of HealthCheckGroups that match those selectors.
+func (c *healthCheckGroups) List(opts v1.ListOptions) (result *v1alpha1.HealthCheckGroupList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.HealthCheckGroupList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested healthCheckGroups.
+func (c *healthCheckGroups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a healthCheckGroup and creates it.  Returns the server's representation of the healthCheckGroup, and an error, if there is any.
+func (c *healthCheckGroups) Create(healthCheckGroup *v1alpha1.HealthCheckGroup) (result *v1alpha1.HealthCheckGroup, err error) {
+	result = &v1alpha1.HealthCheckGroup{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		Body(healthCheckGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a healthCheckGroup and updates it. Returns the server's representation of the healthCheckGroup, and an error, if there is any.
+func (c *healthCheckGroups) Update(healthCheckGroup *v1alpha1.HealthCheckGroup) (result *v1alpha1.HealthCheckGroup, err error) {
+	result = &v1alpha1.HealthCheckGroup{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		Name(healthCheckGroup.Name).
+		Body(healthCheckGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *healthCheckGroups) UpdateStatus(healthCheckGroup *v1alpha1.HealthCheckGroup) (result *v1alpha1.HealthCheckGroup, err error) {
+	result = &v1alpha1.HealthCheckGroup{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		Name(healthCheckGroup.Name).
+		SubResource("status").
+		Body(healthCheckGroup).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the healthCheckGroup and deletes it. Returns an error if one occurs.
+func (c *healthCheckGroups) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *healthCheckGroups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched healthCheckGroup.
+func (c *healthCheckGroups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.HealthCheckGroup, err error) {
+	result = &v1alpha1.HealthCheckGroup{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("healthcheckgroups").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -37,8 +37,12 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=health.mbell.dev, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("clusterhealthchecks"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Health().V1alpha1().ClusterHealthChecks().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("healthchecks"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Health().V1alpha1().HealthChecks().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("healthcheckgroups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Health().V1alpha1().HealthCheckGroups().Informer()}, nil
 
 	}
 
@@ -0,0 +1,72 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	time "time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	versioned "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/mbellgb/healthcheck-controller/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/generated/listers/health/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterHealthCheckInformer provides access to a shared informer and lister for
+// ClusterHealthChecks.
+type ClusterHealthCheckInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.ClusterHealthCheckLister
+}
+
+type clusterHealthCheckInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewClusterHealthCheckInformer constructs a new informer for ClusterHealthCheck type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewClusterHealthCheckInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredClusterHealthCheckInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredClusterHealthCheckInformer constructs a new informer for ClusterHealthCheck type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredClusterHealthCheckInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.HealthV1alpha1().ClusterHealthChecks().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.HealthV1alpha1().ClusterHealthChecks().Watch(options)
+			},
+		},
+		&healthv1alpha1.ClusterHealthCheck{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *clusterHealthCheckInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredClusterHealthCheckInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *clusterHealthCheckInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&healthv1alpha1.ClusterHealthCheck{}, f.defaultInformer)
+}
+
+func (f *clusterHealthCheckInformer) Lister() v1alpha1.ClusterHealthCheckLister {
+	return v1alpha1.NewClusterHealthCheckLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,73 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	time "time"
+
+	healthv1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	versioned "github.com/mbellgb/healthcheck-controller/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/mbellgb/healthcheck-controller/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/generated/listers/health/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// HealthCheckGroupInformer provides access to a shared informer and lister for
+// HealthCheckGroups.
+type HealthCheckGroupInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.HealthCheckGroupLister
+}
+
+type healthCheckGroupInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewHealthCheckGroupInformer constructs a new informer for HealthCheckGroup type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewHealthCheckGroupInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredHealthCheckGroupInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredHealthCheckGroupInformer constructs a new informer for HealthCheckGroup type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredHealthCheckGroupInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.HealthV1alpha1().HealthCheckGroups(namespace).List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.HealthV1alpha1().HealthCheckGroups(namespace).Watch(options)
+			},
+		},
+		&healthv1alpha1.HealthCheckGroup{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *healthCheckGroupInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredHealthCheckGroupInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *healthCheckGroupInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&healthv1alpha1.HealthCheckGroup{}, f.defaultInformer)
+}
+
+func (f *healthCheckGroupInformer) Lister() v1alpha1.HealthCheckGroupLister {
+	return v1alpha1.NewHealthCheckGroupLister(f.Informer().GetIndexer())
+}
@@ -8,8 +8,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterHealthChecks returns a ClusterHealthCheckInformer.
+	ClusterHealthChecks() ClusterHealthCheckInformer
 	// HealthChecks returns a HealthCheckInformer.
 	HealthChecks() HealthCheckInformer
+	// HealthCheckGroups returns a HealthCheckGroupInformer.
+	HealthCheckGroups() HealthCheckGroupInformer
 }
 
 type version struct {
@@ -23,7 +27,17 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ClusterHealthChecks returns a ClusterHealthCheckInformer.
+func (v *version) ClusterHealthChecks() ClusterHealthCheckInformer {
+	return &clusterHealthCheckInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // HealthChecks returns a HealthCheckInformer.
 func (v *version) HealthChecks() HealthCheckInformer {
 	return &healthCheckInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// HealthCheckGroups returns a HealthCheckGroupInformer.
+func (v *version) HealthCheckGroups() HealthCheckGroupInformer {
+	return &healthCheckGroupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,49 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ClusterHealthCheckLister helps list ClusterHealthChecks.
+type ClusterHealthCheckLister interface {
+	// List lists all ClusterHealthChecks in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.ClusterHealthCheck, err error)
+	// Get retrieves the ClusterHealthCheck from the index for a given name.
+	Get(name string) (*v1alpha1.ClusterHealthCheck, error)
+	ClusterHealthCheckListerExpansion
+}
+
+// clusterHealthCheckLister implements the ClusterHealthCheckLister interface.
+type clusterHealthCheckLister struct {
+	indexer cache.Indexer
+}
+
+// NewClusterHealthCheckLister returns a new ClusterHealthCheckLister.
+func NewClusterHealthCheckLister(indexer cache.Indexer) ClusterHealthCheckLister {
+	return &clusterHealthCheckLister{indexer: indexer}
+}
+
+// List lists all ClusterHealthChecks in the indexer.
+func (s *clusterHealthCheckLister) List(selector labels.Selector) (ret []*v1alpha1.ClusterHealthCheck, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.ClusterHealthCheck))
+	})
+	return ret, err
+}
+
+// Get retrieves the ClusterHealthCheck from the index for a given name.
+func (s *clusterHealthCheckLister) Get(name string) (*v1alpha1.ClusterHealthCheck, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("clusterhealthcheck"), name)
+	}
+	return obj.(*v1alpha1.ClusterHealthCheck), nil
+}
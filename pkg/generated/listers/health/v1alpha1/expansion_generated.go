@@ -2,6 +2,10 @@
 
 package v1alpha1
 
+// ClusterHealthCheckListerExpansion allows custom methods to be added to
+// ClusterHealthCheckLister.
+type ClusterHealthCheckListerExpansion interface{}
+
 // HealthCheckListerExpansion allows custom methods to be added to
 // HealthCheckLister.
 type HealthCheckListerExpansion interface{}
@@ -9,3 +13,11 @@ type HealthCheckListerExpansion interface{}
 // HealthCheckNamespaceListerExpansion allows custom methods to be added to
 // HealthCheckNamespaceLister.
 type HealthCheckNamespaceListerExpansion interface{}
+
+// HealthCheckGroupListerExpansion allows custom methods to be added to
+// HealthCheckGroupLister.
+type HealthCheckGroupListerExpansion interface{}
+
+// HealthCheckGroupNamespaceListerExpansion allows custom methods to be added to
+// HealthCheckGroupNamespaceLister.
+type HealthCheckGroupNamespaceListerExpansion interface{}
@@ -0,0 +1,78 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/mbellgb/healthcheck-controller/pkg/apis/health/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// HealthCheckGroupLister helps list HealthCheckGroups.
+type HealthCheckGroupLister interface {
+	// List lists all HealthCheckGroups in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.HealthCheckGroup, err error)
+	// HealthCheckGroups returns an object that can list and get HealthCheckGroups.
+	HealthCheckGroups(namespace string) HealthCheckGroupNamespaceLister
+	HealthCheckGroupListerExpansion
+}
+
+// healthCheckGroupLister implements the HealthCheckGroupLister interface.
+type healthCheckGroupLister struct {
+	indexer cache.Indexer
+}
+
+// NewHealthCheckGroupLister returns a new HealthCheckGroupLister.
+func NewHealthCheckGroupLister(indexer cache.Indexer) HealthCheckGroupLister {
+	return &healthCheckGroupLister{indexer: indexer}
+}
+
+// List lists all HealthCheckGroups in the indexer.
+func (s *healthCheckGroupLister) List(selector labels.Selector) (ret []*v1alpha1.HealthCheckGroup, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.HealthCheckGroup))
+	})
+	return ret, err
+}
+
+// HealthCheckGroups returns an object that can list and get HealthCheckGroups.
+func (s *healthCheckGroupLister) HealthCheckGroups(namespace string) HealthCheckGroupNamespaceLister {
+	return healthCheckGroupNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// HealthCheckGroupNamespaceLister helps list and get HealthCheckGroups.
+type HealthCheckGroupNamespaceLister interface {
+	// List lists all HealthCheckGroups in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.HealthCheckGroup, err error)
+	// Get retrieves the HealthCheckGroup from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.HealthCheckGroup, error)
+	HealthCheckGroupNamespaceListerExpansion
+}
+
+// healthCheckGroupNamespaceLister implements the HealthCheckGroupNamespaceLister
+// interface.
+type healthCheckGroupNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all HealthCheckGroups in the indexer for a given namespace.
+func (s healthCheckGroupNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.HealthCheckGroup, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.HealthCheckGroup))
+	})
+	return ret, err
+}
+
+// Get retrieves the HealthCheckGroup from the indexer for a given namespace and name.
+func (s healthCheckGroupNamespaceLister) Get(name string) (*v1alpha1.HealthCheckGroup, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("healthcheckgroup"), name)
+	}
+	return obj.(*v1alpha1.HealthCheckGroup), nil
+}